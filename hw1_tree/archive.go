@@ -0,0 +1,28 @@
+package main
+
+import (
+	"os"
+
+	"hw1_tree/tree"
+)
+
+// openArchive makes the archive the file system of the traversal rooted
+// at its path. Other paths are returned as they are. The returned function
+// closes the archive.
+func openArchive(name string, opts options) (options, func(), error) {
+	info, err := os.Stat(name)
+
+	if err != nil || info.IsDir() || !tree.IsArchive(name) {
+		return opts, func() {}, nil
+	}
+
+	archive, err := tree.OpenArchive(name)
+
+	if err != nil {
+		return opts, nil, err
+	}
+
+	opts.fsys = archive
+
+	return opts, func() { archive.Close() }, nil
+}
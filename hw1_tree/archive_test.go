@@ -0,0 +1,181 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"testing"
+
+	"hw1_tree/tree"
+)
+
+var archiveFiles = map[string]string{
+	"README.md":        "# archive\n",
+	"src/main.go":      "package main\n",
+	"src/util/util.go": "package util\n",
+}
+
+func sortedNames(files map[string]string) []string {
+	names := make([]string, 0, len(files))
+
+	for name := range files {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// writeZip stores files only, so directories of the archive are implicit.
+func writeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	for _, name := range sortedNames(files) {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(files[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeTarGz stores files and then links keyed by their names.
+func writeTarGz(t *testing.T, path string, files map[string]string, links map[string]string) {
+	t.Helper()
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	gz := gzip.NewWriter(file)
+	archive := tar.NewWriter(gz)
+	for _, name := range sortedNames(files) {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(files[name])), Typeflag: tar.TypeReg}
+		if err := archive.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := archive.Write([]byte(files[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range sortedNames(links) {
+		header := &tar.Header{Name: name, Linkname: links[name], Mode: 0777, Typeflag: tar.TypeSymlink}
+		if err := archive.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func checkArchive(t *testing.T, path string, opts options, expected string) {
+	t.Helper()
+
+	opts, closeArchive, err := openArchive(path, opts)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	defer closeArchive()
+
+	if opts.fsys == nil {
+		t.Fatalf("%s is not opened as an archive", path)
+	}
+
+	checkTree(t, path, opts, expected)
+}
+
+const zipResult = "├───README.md (10b) crc32:6b88a8ce\n" +
+	"└───src\n" +
+	"	├───main.go (13b) crc32:17c733ec\n" +
+	"	└───util\n" +
+	"		└───util.go (13b) crc32:12a62a80\n"
+
+func TestTreeZip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "files.zip")
+	writeZip(t, path, archiveFiles)
+
+	checkArchive(t, path, options{keepFiles: true, color: colorNever, hash: tree.HashCRC32}, zipResult)
+	checkArchive(t, path, options{keepFiles: true, color: colorNever, hash: tree.HashCRC32, stream: true}, zipResult)
+}
+
+const tarResult = "├───README.md (10b)\n" +
+	"├───latest -> README.md (9b)\n" +
+	"└───src\n" +
+	"	├───main.go (13b)\n" +
+	"	└───util\n" +
+	"		└───util.go (13b)\n"
+
+const tarGrepResult = "└───src\n" +
+	"	├───main.go (13b) [1 matching line]\n" +
+	"	└───util\n" +
+	"		└───util.go (13b) [1 matching line]\n"
+
+func TestTreeTarGz(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "files.tar.gz")
+	writeTarGz(t, path, archiveFiles, map[string]string{"latest": "README.md"})
+
+	checkArchive(t, path, options{keepFiles: true, color: colorNever, showLinks: true}, tarResult)
+	checkArchive(t, path, options{keepFiles: true, color: colorNever, grep: regexp.MustCompile("^package")}, tarGrepResult)
+}
+
+const tarLinksResult = "├───README.md (10b) crc32:6b88a8ce\n" +
+	"├───lib -> src/util\n" +
+	"│	├───readme -> ../../README.md (15b)\n" +
+	"│	└───util.go (13b) crc32:12a62a80\n" +
+	"├───outside -> ../etc (6b)\n" +
+	"└───src\n" +
+	"	├───main.go (13b) crc32:17c733ec\n" +
+	"	└───util\n" +
+	"		├───readme -> ../../README.md (15b)\n" +
+	"		└───util.go (13b) crc32:12a62a80\n"
+
+func TestTreeTarLinks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "files.tar.gz")
+	writeTarGz(t, path, archiveFiles, map[string]string{
+		"lib":             "src/util",
+		"outside":         "../etc",
+		"src/util/readme": "../../README.md",
+	})
+
+	checkArchive(t, path, options{keepFiles: true, color: colorNever, showLinks: true, followLinks: true, hash: tree.HashCRC32}, tarLinksResult)
+}
+
+func TestOpenArchiveErrors(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"broken.zip": "not a zip", "broken.tgz": "not a tar"})
+
+	for _, name := range []string{"broken.zip", "broken.tgz"} {
+		if _, _, err := openArchive(filepath.Join(root, name), options{}); err == nil {
+			t.Errorf("expected error for %s", name)
+		}
+	}
+
+	opts, closeArchive, err := openArchive("testdata", options{})
+	if err != nil || opts.fsys != nil {
+		t.Errorf("directory is opened as an archive: %v", err)
+	}
+	closeArchive()
+}
@@ -0,0 +1,70 @@
+package main
+
+import "strings"
+
+const (
+	charsetUnicode = iota
+	charsetASCII
+	// charsetCompact draws nothing, levels are told by indentation only
+	charsetCompact
+)
+
+var charsetNames = map[string]int{
+	"unicode": charsetUnicode,
+	"ascii":   charsetASCII,
+	"compact": charsetCompact,
+}
+
+// charset holds characters tree lines are drawn with. vertical continues
+// a directory with more entries below, branch and last connect entries to
+// it and horizontal extends connectors to the indent width.
+type charset struct {
+	vertical   string
+	branch     string
+	last       string
+	horizontal string
+	// indent is the default indent width, 0 indents with tabs
+	indent int
+}
+
+var charsets = map[int]charset{
+	charsetUnicode: {vertical: "│", branch: "├", last: "└", horizontal: "─"},
+	charsetASCII:   {vertical: "|", branch: "|", last: "`", horizontal: "-"},
+	charsetCompact: {vertical: " ", branch: " ", last: " ", horizontal: " ", indent: 2},
+}
+
+// treeLines are pieces of tree lines ready to print. pipe and space go
+// into the prefix below directories which are not last and which are last.
+type treeLines struct {
+	pipe   string
+	space  string
+	branch string
+	last   string
+}
+
+// newTreeLines draws lines with the charset indented by the width, 0 keeps
+// the default of the charset.
+func newTreeLines(charsetID int, width int) treeLines {
+	c := charsets[charsetID]
+
+	if width == 0 {
+		width = c.indent
+	}
+
+	// tabs keep connectors as wide as they always were
+	if width == 0 {
+		connector := strings.Repeat(c.horizontal, 3)
+
+		return treeLines{pipe: c.vertical + "\t", space: "\t", branch: c.branch + connector, last: c.last + connector}
+	}
+
+	connector := strings.Repeat(c.horizontal, width-1)
+	padding := strings.Repeat(" ", width-1)
+
+	return treeLines{
+		pipe:   c.vertical + padding,
+		space:  " " + padding,
+		branch: c.branch + connector,
+		last:   c.last + connector,
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestNewTreeLines(t *testing.T) {
+	cases := []struct {
+		charset  int
+		width    int
+		expected treeLines
+	}{
+		{charsetUnicode, 0, treeLines{pipe: "│\t", space: "\t", branch: "├───", last: "└───"}},
+		{charsetUnicode, 4, treeLines{pipe: "│   ", space: "    ", branch: "├───", last: "└───"}},
+		{charsetASCII, 0, treeLines{pipe: "|\t", space: "\t", branch: "|---", last: "`---"}},
+		{charsetASCII, 1, treeLines{pipe: "|", space: " ", branch: "|", last: "`"}},
+		{charsetCompact, 0, treeLines{pipe: "  ", space: "  ", branch: "  ", last: "  "}},
+		{charsetCompact, 3, treeLines{pipe: "   ", space: "   ", branch: "   ", last: "   "}},
+	}
+
+	for _, c := range cases {
+		if result := newTreeLines(c.charset, c.width); result != c.expected {
+			t.Errorf("charset %d, width %d: expected %q, got %q", c.charset, c.width, c.expected, result)
+		}
+	}
+}
+
+const asciiResult = "|--a_lorem\n" +
+	"|  |--dolor.txt (empty)\n" +
+	"|  |--gopher.png (70372b)\n" +
+	"|  `--ipsum\n" +
+	"|     `--... (1 entry)\n" +
+	"`--css\n" +
+	"   `--body.css (28b)\n"
+
+const compactResult = "  a_lorem\n" +
+	"    ipsum\n" +
+	"  css\n" +
+	"  html\n" +
+	"  js\n" +
+	"  z_lorem\n" +
+	"    ipsum\n"
+
+func TestTreeCharsets(t *testing.T) {
+	checkTree(t, "testdata/static", options{keepFiles: true, color: colorNever, charset: charsetASCII, indent: 3, maxDepth: 2, include: []string{"*.txt", "*.png", "*.css"}, exclude: []string{"z_lorem", "html", "js", "empty.txt"}}, asciiResult)
+	checkTree(t, "testdata/static", options{color: colorNever, charset: charsetCompact}, compactResult)
+}
@@ -0,0 +1,530 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"hw1_tree/tree"
+)
+
+// exit codes of the command
+const (
+	exitOK = iota
+	// exitError means some directories could not be read
+	exitError
+	exitUsage
+)
+
+const usageLine = "usage: tree [flags] [path...]"
+
+// errHelp is returned by parseArgs when the help is requested.
+var errHelp = errors.New("help requested")
+
+var hashAlgorithms = map[string]int{
+	"sha256": tree.HashSHA256,
+	"md5":    tree.HashMD5,
+	"crc32":  tree.HashCRC32,
+}
+
+// cliFlag is a command line flag, short and long are its names without
+// dashes, either can be empty. Flags with a value describe it in arg.
+type cliFlag struct {
+	short string
+	long  string
+	arg   string
+	help  string
+	set   func(opts *options, value string) error
+}
+
+var cliFlags = []cliFlag{
+	{short: "f", help: "include files", set: func(opts *options, _ string) error {
+		opts.keepFiles = true
+
+		return nil
+	}},
+	{short: "a", help: "include entries whose names start with a dot", set: func(opts *options, _ string) error {
+		opts.showHidden = true
+
+		return nil
+	}},
+	{short: "P", arg: "pattern", help: "list only files matching the pattern, repeatable", set: func(opts *options, value string) error {
+		return addPattern(&opts.include, value)
+	}},
+	{short: "I", arg: "pattern", help: "skip files and directories matching the pattern, repeatable", set: func(opts *options, value string) error {
+		return addPattern(&opts.exclude, value)
+	}},
+	{long: "grep", arg: "regexp", help: "list only files with lines matching the regexp", set: func(opts *options, value string) error {
+		pattern, err := regexp.Compile(value)
+
+		if err != nil {
+			return fmt.Errorf("bad regexp %q: %s", value, err)
+		}
+
+		opts.grep = pattern
+
+		return nil
+	}},
+	{long: "min-size", arg: "size", help: "list only files of at least size, e.g. 512, 10K or 1.5MB", set: func(opts *options, value string) error {
+		size, err := parseSize(value)
+		opts.minSize = size
+
+		return err
+	}},
+	{long: "max-size", arg: "size", help: "list only files of at most size", set: func(opts *options, value string) error {
+		size, err := parseSize(value)
+		opts.maxSize = size
+
+		return err
+	}},
+	{long: "newer", arg: "time", help: "list only files modified since time, a date, RFC 3339 time or age like 7d", set: func(opts *options, value string) error {
+		t, err := parseTime(value, time.Now())
+		opts.newer = t
+
+		return err
+	}},
+	{long: "older", arg: "time", help: "list only files modified before time", set: func(opts *options, value string) error {
+		t, err := parseTime(value, time.Now())
+		opts.older = t
+
+		return err
+	}},
+	{long: "type", arg: "types", help: "list only regular, dir, symlink, socket, device or executable entries, comma separated", set: func(opts *options, value string) error {
+		types, err := parseTypes(value)
+		opts.types = types
+
+		return err
+	}},
+	{long: "gitignore", help: "skip entries ignored by .gitignore files", set: func(opts *options, _ string) error {
+		opts.gitIgnore = true
+
+		return nil
+	}},
+	{short: "L", arg: "level", help: "descend at most level directories deep", set: func(opts *options, value string) error {
+		level, err := strconv.Atoi(value)
+
+		if err != nil || level < 1 {
+			return fmt.Errorf("invalid level %q, must be greater than 0", value)
+		}
+
+		opts.maxDepth = level
+
+		return nil
+	}},
+	{long: "du", help: "show cumulative sizes of directories", set: func(opts *options, _ string) error {
+		opts.du = true
+
+		return nil
+	}},
+	{short: "h", help: "print sizes in powers of 1024 (68.7KiB)", set: func(opts *options, _ string) error {
+		opts.sizeUnits = sizeBinary
+
+		return nil
+	}},
+	{long: "si", help: "print sizes in powers of 1000 (70.4kB)", set: func(opts *options, _ string) error {
+		opts.sizeUnits = sizeSI
+
+		return nil
+	}},
+	{long: "apparent-size", help: "print sizes of the content (default)", set: func(opts *options, _ string) error {
+		opts.allocated = false
+
+		return nil
+	}},
+	{long: "allocated-size", help: "print sizes of allocated disk blocks", set: func(opts *options, _ string) error {
+		opts.allocated = true
+
+		return nil
+	}},
+	{long: "sort", arg: "order", help: "sort by name, size, mtime, extension or version", set: func(opts *options, value string) error {
+		sortBy, ok := sortOrders[value]
+
+		if !ok {
+			return fmt.Errorf("invalid sort order %q", value)
+		}
+
+		opts.sortBy = sortBy
+
+		return nil
+	}},
+	{short: "t", help: "sort by modification time, newest first", set: func(opts *options, _ string) error {
+		opts.sortBy = sortByTime
+
+		return nil
+	}},
+	{short: "v", help: "sort by version", set: func(opts *options, _ string) error {
+		opts.sortBy = sortByVersion
+
+		return nil
+	}},
+	{short: "r", help: "reverse the sort order", set: func(opts *options, _ string) error {
+		opts.reverse = true
+
+		return nil
+	}},
+	{short: "U", help: "stream entries unsorted in the order of the file system", set: func(opts *options, _ string) error {
+		opts.stream = true
+
+		return nil
+	}},
+	{long: "dirsfirst", help: "list directories before files", set: func(opts *options, _ string) error {
+		opts.dirsFirst = true
+
+		return nil
+	}},
+	{long: "links", help: "show targets of symbolic links", set: func(opts *options, _ string) error {
+		opts.showLinks = true
+
+		return nil
+	}},
+	{short: "l", help: "follow symbolic links to directories", set: func(opts *options, _ string) error {
+		opts.followLinks = true
+
+		return nil
+	}},
+	{short: "j", arg: "jobs", help: "read directories ahead and hash files with jobs workers", set: func(opts *options, value string) error {
+		jobs, err := strconv.Atoi(value)
+
+		if err != nil || jobs < 1 {
+			return fmt.Errorf("invalid number of jobs %q, must be greater than 0", value)
+		}
+
+		opts.jobs = jobs
+
+		return nil
+	}},
+	{short: "J", long: "json", help: "print the tree as JSON", set: func(opts *options, _ string) error {
+		opts.format = formatJSON
+
+		return nil
+	}},
+	{short: "X", long: "xml", help: "print the tree as XML", set: func(opts *options, _ string) error {
+		opts.format = formatXML
+
+		return nil
+	}},
+	{short: "H", long: "html", arg: "base", help: "print the tree as an HTML page linking files to base", set: func(opts *options, value string) error {
+		opts.format = formatHTML
+		opts.htmlBase = value
+
+		return nil
+	}},
+	{long: "charset", arg: "name", help: "draw lines with unicode, ascii or compact (indentation only)", set: func(opts *options, value string) error {
+		charset, ok := charsetNames[value]
+
+		if !ok {
+			return fmt.Errorf("invalid charset %q", value)
+		}
+
+		opts.charset = charset
+
+		return nil
+	}},
+	{long: "indent", arg: "width", help: "indent levels by width columns instead of tabs", set: func(opts *options, value string) error {
+		width, err := strconv.Atoi(value)
+
+		if err != nil || width < 1 {
+			return fmt.Errorf("invalid indent width %q, must be greater than 0", value)
+		}
+
+		opts.indent = width
+
+		return nil
+	}},
+	{long: "color", arg: "when", help: "colorize the output: auto, always or never", set: func(opts *options, value string) error {
+		switch value {
+		case "auto":
+			opts.color = colorAuto
+		case "always":
+			opts.color = colorAlways
+		case "never":
+			opts.color = colorNever
+		default:
+			return fmt.Errorf("invalid color mode %q", value)
+		}
+
+		return nil
+	}},
+	{long: "diff", arg: "old", help: "compare roots to the old directory or JSON snapshot", set: func(opts *options, value string) error {
+		opts.diffBase = value
+
+		return nil
+	}},
+	{long: "watch", help: "keep running and print the tree again when it changes", set: func(opts *options, _ string) error {
+		opts.watch = watchRender
+
+		return nil
+	}},
+	{long: "watch-events", help: "keep running and print added, removed and changed entries", set: func(opts *options, _ string) error {
+		opts.watch = watchEvents
+
+		return nil
+	}},
+	{long: "hash", arg: "algorithm", help: "show checksums of files: sha256, md5 or crc32", set: func(opts *options, value string) error {
+		algorithm, ok := hashAlgorithms[value]
+
+		if !ok {
+			return fmt.Errorf("invalid hash algorithm %q", value)
+		}
+
+		opts.hash = algorithm
+
+		return nil
+	}},
+	{long: "inodes", help: "show inode numbers", set: func(opts *options, _ string) error {
+		opts.columns |= columnInode
+
+		return nil
+	}},
+	{short: "p", help: "show permissions", set: func(opts *options, _ string) error {
+		opts.columns |= columnPerms
+
+		return nil
+	}},
+	{short: "u", help: "show owners", set: func(opts *options, _ string) error {
+		opts.columns |= columnUser
+
+		return nil
+	}},
+	{short: "g", help: "show groups", set: func(opts *options, _ string) error {
+		opts.columns |= columnGroup
+
+		return nil
+	}},
+	{short: "D", help: "show modification times", set: func(opts *options, _ string) error {
+		opts.columns |= columnTime
+
+		return nil
+	}},
+	{long: "full-path", help: "show paths from the root instead of names", set: func(opts *options, _ string) error {
+		opts.columns |= columnFullPath
+
+		return nil
+	}},
+	{long: "noreport", help: "omit the totals after the tree", set: func(opts *options, _ string) error {
+		opts.report = false
+
+		return nil
+	}},
+	{long: "report-types", help: "count files by extension in the totals", set: func(opts *options, _ string) error {
+		opts.reportTypes = true
+
+		return nil
+	}},
+	{long: "help", help: "print this help and exit", set: func(_ *options, _ string) error {
+		return errHelp
+	}},
+}
+
+func addPattern(patterns *[]string, pattern string) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("bad pattern %q: %s", pattern, err)
+	}
+
+	*patterns = append(*patterns, pattern)
+
+	return nil
+}
+
+func findFlag(name string, long bool) *cliFlag {
+	for i := range cliFlags {
+		if long && cliFlags[i].long == name || !long && cliFlags[i].short == name {
+			return &cliFlags[i]
+		}
+	}
+
+	return nil
+}
+
+// parseArgs reads flags and root paths in any order. Short flags can be
+// combined (-fh) and take a value glued (-L2) or as the next argument,
+// long ones take it after an equals sign or as the next argument.
+// Everything after -- is a path. The current directory is listed when no
+// path is given.
+func parseArgs(args []string) ([]string, options, error) {
+	opts := options{color: colorAuto, colors: colorSchemeFromEnv(), report: true}
+	paths := []string{}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch {
+		case arg == "--":
+			paths = append(paths, args[i+1:]...)
+			i = len(args)
+		case strings.HasPrefix(arg, "--"):
+			name, value, hasValue := strings.Cut(arg[2:], "=")
+			f := findFlag(name, true)
+
+			if f == nil {
+				return nil, opts, fmt.Errorf("unknown flag --%s", name)
+			}
+
+			if f.arg == "" && hasValue {
+				return nil, opts, fmt.Errorf("flag --%s does not take a value", name)
+			}
+
+			if f.arg != "" && !hasValue {
+				if i == len(args)-1 {
+					return nil, opts, fmt.Errorf("flag --%s requires a %s", name, f.arg)
+				}
+
+				i++
+				value = args[i]
+			}
+
+			if err := f.set(&opts, value); err != nil {
+				return nil, opts, err
+			}
+		case len(arg) > 1 && arg[0] == '-':
+			for j := 1; j < len(arg); j++ {
+				name := arg[j : j+1]
+				f := findFlag(name, false)
+
+				if f == nil {
+					return nil, opts, fmt.Errorf("unknown flag -%s", name)
+				}
+
+				value := ""
+
+				if f.arg != "" {
+					value = arg[j+1:]
+					j = len(arg)
+
+					if value == "" {
+						if i == len(args)-1 {
+							return nil, opts, fmt.Errorf("flag -%s requires a %s", name, f.arg)
+						}
+
+						i++
+						value = args[i]
+					}
+				}
+
+				if err := f.set(&opts, value); err != nil {
+					return nil, opts, err
+				}
+			}
+		default:
+			paths = append(paths, arg)
+		}
+	}
+
+	// every root would be a document of its own, which do not parse
+	// together
+	if opts.format != formatText && len(paths) > 1 {
+		return nil, opts, errors.New("JSON, XML and HTML output takes a single root")
+	}
+
+	if opts.watch != watchNone && (len(paths) > 1 || opts.diffBase != "") {
+		return nil, opts, errors.New("watching takes a single root and no --diff")
+	}
+
+	if len(paths) == 0 {
+		paths = append(paths, ".")
+	}
+
+	return paths, opts, nil
+}
+
+// printOutHelp lists the flags with their descriptions in one column.
+func printOutHelp(out io.Writer) {
+	names := make([]string, len(cliFlags))
+	width := 0
+
+	for i, f := range cliFlags {
+		var forms []string
+
+		if f.short != "" {
+			forms = append(forms, "-"+f.short)
+		}
+
+		if f.long != "" {
+			forms = append(forms, "--"+f.long)
+		}
+
+		names[i] = strings.Join(forms, ", ")
+
+		if f.arg != "" {
+			names[i] += " " + f.arg
+		}
+
+		if len(names[i]) > width {
+			width = len(names[i])
+		}
+	}
+
+	fmt.Fprintf(out, "%s\n\nPrints the tree of every path, the current directory by default.\n\nFlags:\n", usageLine)
+
+	for i, f := range cliFlags {
+		fmt.Fprintf(out, "  %-*s  %s\n", width, names[i], f.help)
+	}
+}
+
+// listRoot prints the tree of a directory or of an archive.
+func listRoot(out io.Writer, path string, opts options) error {
+	opts, closeArchive, err := openArchive(path, opts)
+
+	if err != nil {
+		return err
+	}
+
+	defer closeArchive()
+
+	return dirTreeIterative(out, path, opts)
+}
+
+// run executes the command and returns its exit code. Every root is
+// listed even if some of them fail, roots are titled with their paths
+// in text output when there are several of them.
+func run(args []string, out io.Writer, errOut io.Writer) int {
+	paths, opts, err := parseArgs(args)
+
+	if errors.Is(err, errHelp) {
+		printOutHelp(out)
+
+		return exitOK
+	}
+
+	if err != nil {
+		fmt.Fprintf(errOut, "tree: %s\n%s\nTry 'tree --help' for more information.\n", err, usageLine)
+
+		return exitUsage
+	}
+
+	return listRoots(paths, opts, out, errOut)
+}
+
+// listRoots prints every root in turn and returns the exit code.
+func listRoots(paths []string, opts options, out io.Writer, errOut io.Writer) int {
+	code := exitOK
+
+	for _, path := range paths {
+		var err error
+
+		if len(paths) > 1 && opts.format == formatText {
+			fmt.Fprintln(out, path)
+		}
+
+		switch {
+		case opts.watch != watchNone:
+			err = watchTree(out, errOut, path, opts, nil)
+		case opts.diffBase != "":
+			err = dirTreeDiff(out, opts.diffBase, path, opts)
+		default:
+			err = listRoot(out, path, opts)
+		}
+
+		if err != nil {
+			fmt.Fprintf(errOut, "tree: %s\n", err)
+			code = exitError
+		}
+	}
+
+	return code
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	paths, opts, err := parseArgs([]string{"-fhL2", "a", "--sort=size", "-P", "*.go", "-I*_test.go", "--color", "never", "b", "--", "-r"})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	if !reflect.DeepEqual(paths, []string{"a", "b", "-r"}) {
+		t.Errorf("unexpected paths %v", paths)
+	}
+
+	if !opts.keepFiles || opts.sizeUnits != sizeBinary || opts.maxDepth != 2 || opts.sortBy != sortBySize || opts.reverse || opts.color != colorNever {
+		t.Errorf("unexpected options %+v", opts)
+	}
+
+	if !reflect.DeepEqual(opts.include, []string{"*.go"}) || !reflect.DeepEqual(opts.exclude, []string{"*_test.go"}) {
+		t.Errorf("unexpected patterns %v %v", opts.include, opts.exclude)
+	}
+}
+
+func TestParseArgsDefaultPath(t *testing.T) {
+	paths, _, err := parseArgs([]string{"-f"})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	if !reflect.DeepEqual(paths, []string{"."}) {
+		t.Errorf("unexpected paths %v", paths)
+	}
+}
+
+func TestParseArgsErrors(t *testing.T) {
+	cases := [][]string{
+		{"-Q"},
+		{"--unknown"},
+		{"-fL"},
+		{"-L", "0"},
+		{"--sort", "color"},
+		{"--du=yes"},
+		{"-P", "["},
+		{"-j", "x"},
+		{"--charset", "ebcdic"},
+		{"--indent", "0"},
+		{"--watch", "a", "b"},
+		{"-J", "a", "b"},
+		{"-X", "a", "b"},
+		{"-H", "base", "a", "b"},
+		{"--watch-events", "--diff", "old", "."},
+	}
+
+	for _, args := range cases {
+		if _, _, err := parseArgs(args); err == nil {
+			t.Errorf("%v: expected error", args)
+		}
+	}
+}
+
+func TestRunExitCodes(t *testing.T) {
+	cases := []struct {
+		args     []string
+		expected int
+	}{
+		{[]string{"testdata/project"}, exitOK},
+		{[]string{"--help"}, exitOK},
+		{[]string{"-L"}, exitUsage},
+		{[]string{"testdata/project", "testdata/missing"}, exitError},
+	}
+
+	for _, c := range cases {
+		out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+		if code := run(c.args, out, errOut); code != c.expected {
+			t.Errorf("%v: expected exit code %d, got %d\n%s", c.args, c.expected, code, errOut)
+		}
+	}
+}
+
+const multipleRootsResult = "testdata/project\n" +
+	"├───file.txt (19b)\n" +
+	"└───gopher.png (70372b)\n" +
+	"testdata/static/css\n" +
+	"└───body.css (28b)\n"
+
+func TestRunMultipleRoots(t *testing.T) {
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	run([]string{"-f", "--color=never", "--noreport", "testdata/project", "testdata/static/css"}, out, errOut)
+
+	if result := out.String(); result != multipleRootsResult {
+		t.Errorf("results not match\nGot:\n%v\nExpected:\n%v", result, multipleRootsResult)
+	}
+
+	out.Reset()
+	run([]string{"--help"}, out, errOut)
+
+	if !strings.Contains(out.String(), "-L level") {
+		t.Errorf("help does not list flags\n%s", out)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+// colorAlways is the zero value, so output of dirTree stays colored as
+// it always was. The command line defaults to colorAuto.
+const (
+	colorAlways = iota
+	colorAuto
+	colorNever
+)
+
+const defaultColors = "di=1;34:fi=1;32:sz=1;35"
+
+// colorScheme holds SGR codes keyed the same way as in LS_COLORS: two
+// letter file types (di, fi, ln, ex, ...) and "*.ext" suffixes. The "sz"
+// key is used for file sizes.
+type colorScheme struct {
+	types      map[string]string
+	extensions map[string]string
+}
+
+// newColorScheme applies spec on top of the default colors.
+func newColorScheme(spec string) *colorScheme {
+	colors := &colorScheme{
+		types:      make(map[string]string),
+		extensions: make(map[string]string),
+	}
+
+	colors.apply(defaultColors)
+	colors.apply(spec)
+
+	return colors
+}
+
+func colorSchemeFromEnv() *colorScheme {
+	spec := os.Getenv("TREE_COLORS")
+
+	if spec == "" {
+		spec = os.Getenv("LS_COLORS")
+	}
+
+	return newColorScheme(spec)
+}
+
+func (c *colorScheme) apply(spec string) {
+	for _, item := range strings.Split(spec, ":") {
+		key, code, ok := strings.Cut(item, "=")
+
+		if !ok || !isColorCode(code) {
+			continue
+		}
+
+		if strings.HasPrefix(key, "*") {
+			c.extensions[strings.ToLower(strings.TrimPrefix(key, "*"))] = code
+		} else {
+			c.types[key] = code
+		}
+	}
+}
+
+func isColorCode(code string) bool {
+	for _, char := range code {
+		if (char < '0' || char > '9') && char != ';' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *colorScheme) fileColor(file os.FileInfo) string {
+	mode := file.Mode()
+
+	switch {
+	case mode.IsDir():
+		return c.types["di"]
+	case mode&os.ModeSymlink != 0:
+		return c.types["ln"]
+	case mode&os.ModeNamedPipe != 0:
+		return c.types["pi"]
+	case mode&os.ModeSocket != 0:
+		return c.types["so"]
+	case mode&os.ModeCharDevice != 0:
+		return c.types["cd"]
+	case mode&os.ModeDevice != 0:
+		return c.types["bd"]
+	}
+
+	if code, ok := c.types["ex"]; ok && mode&0111 != 0 {
+		return code
+	}
+
+	// the longest matching suffix wins, so "*.tar.gz" beats "*.gz"
+	name := strings.ToLower(file.Name())
+	code, matched := c.types["fi"], 0
+
+	for suffix, suffixCode := range c.extensions {
+		if len(suffix) > matched && strings.HasSuffix(name, suffix) {
+			code, matched = suffixCode, len(suffix)
+		}
+	}
+
+	return code
+}
+
+// paint wraps text into escape sequences, nil scheme leaves it as is.
+func (c *colorScheme) paint(code string, text string) string {
+	if c == nil || code == "" {
+		return text
+	}
+
+	return "\033[" + code + "m" + text + "\033[0;m"
+}
+
+func (c *colorScheme) name(file os.FileInfo) string {
+	if c == nil {
+		return file.Name()
+	}
+
+	return c.paint(c.fileColor(file), file.Name())
+}
+
+func (c *colorScheme) size(text string) string {
+	if c == nil {
+		return text
+	}
+
+	return c.paint(c.types["sz"], text)
+}
+
+// colorsFor returns nil when output should not be colored.
+func colorsFor(out io.Writer, opts options) *colorScheme {
+	switch opts.color {
+	case colorNever:
+		return nil
+	case colorAuto:
+		if os.Getenv("NO_COLOR") != "" || !isTerminal(out) {
+			return nil
+		}
+	}
+
+	if opts.colors == nil {
+		return newColorScheme("")
+	}
+
+	return opts.colors
+}
+
+func isTerminal(out io.Writer) bool {
+	file, ok := out.(*os.File)
+
+	if !ok {
+		return false
+	}
+
+	info, err := file.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+const noColorResult = "├───project\n" +
+	"│	├───file.txt (19b)\n" +
+	"│	└───gopher.png (70372b)\n" +
+	"└───zline\n" +
+	"	├───empty.txt (empty)\n" +
+	"	└───lorem\n" +
+	"		└───... (3 entries)\n"
+
+func TestTreeColorNever(t *testing.T) {
+	opts := options{keepFiles: true, color: colorNever, include: []string{"*.txt", "*.png"}, exclude: []string{"static", "zz*"}, maxDepth: 2}
+
+	checkTree(t, "testdata", opts, noColorResult)
+}
+
+func TestTreeColorAuto(t *testing.T) {
+	// bytes.Buffer is not a terminal
+	checkTree(t, "testdata/project", options{keepFiles: true, color: colorAuto}, "├───file.txt (19b)\n└───gopher.png (70372b)\n")
+}
+
+const colorSchemeResult = "├───\033[0;33mfile.txt\033[0;m (19b)\n" +
+	"└───\033[1;32mgopher.png\033[0;m (70372b)\n"
+
+func TestTreeColorScheme(t *testing.T) {
+	opts := options{keepFiles: true, colors: newColorScheme("sz=:*.txt=0;33:*.TAR.GZ=1")}
+
+	checkTree(t, "testdata/project", opts, colorSchemeResult)
+}
+
+func TestColorSchemeSuffix(t *testing.T) {
+	colors := newColorScheme("*.gz=1;31:*.tar.gz=1;33:ln=invalid")
+	cases := map[string]string{
+		"archive.tar.gz": "1;33",
+		"archive.GZ":     "1;31",
+		"archive.tgz":    "1;32",
+	}
+
+	for name, expected := range cases {
+		if code := colors.fileColor(testFileInfo{name: name}); code != expected {
+			t.Errorf("%s: expected color %q, got %q", name, expected, code)
+		}
+	}
+
+	if _, ok := colors.types["ln"]; ok {
+		t.Errorf("expected invalid color code to be skipped")
+	}
+}
+
+func TestColorNoColorEnv(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	if colorsFor(new(bytes.Buffer), options{color: colorAuto}) != nil {
+		t.Errorf("expected no colors")
+	}
+	if colorsFor(new(bytes.Buffer), options{color: colorAlways}) == nil {
+		t.Errorf("expected colors when forced")
+	}
+}
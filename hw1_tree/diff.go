@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+
+	"hw1_tree/tree"
+)
+
+var diffNames = map[int]string{
+	tree.Added:   "added",
+	tree.Removed: "removed",
+	tree.Changed: "changed",
+}
+
+// dirTreeDiff renders a tree merged from the old and the new root. The old
+// one is either a directory read the same way as the new one or a JSON
+// snapshot written by the JSON renderer. Directories are compared whole,
+// the depth limit only cuts the output, and directories cut off with
+// changes inside are marked changed.
+func dirTreeDiff(out io.Writer, oldPath string, newPath string, opts options) error {
+	loadOpts := opts
+	loadOpts.maxDepth = 0
+
+	oldRoot, oldErr := loadSide(oldPath, loadOpts)
+
+	if oldRoot == nil {
+		return oldErr
+	}
+
+	newRoot, newErr := loadTree(newPath, loadOpts)
+
+	if newRoot == nil {
+		return errors.Join(oldErr, newErr)
+	}
+
+	newRoot.Children = markTruncated(tree.Merge(oldRoot.Children, newRoot.Children, lessFunc(opts), false), opts, 1)
+
+	return errors.Join(renderLoaded(out, newPath, newRoot, opts), oldErr, newErr)
+}
+
+// renderLoaded renders a tree loaded down to the depth limit, so only
+// checksums of files are read during the traversal.
+func renderLoaded(out io.Writer, path string, root *tree.Node, opts options) error {
+	w, err := newWalker(path, opts)
+
+	if err != nil {
+		return err
+	}
+
+	defer w.Close()
+
+	files, err := w.Open(root)
+
+	if err != nil {
+		return err
+	}
+
+	r := newRenderer(out, path, root, opts)
+
+	dirTreeRecursiveInner(r, w, path, files, opts, 1)
+
+	return errors.Join(r.finish(), w.Err())
+}
+
+func loadSide(path string, opts options) (*tree.Node, error) {
+	info, err := os.Stat(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return loadTree(path, opts)
+	}
+
+	return readSnapshot(path, opts)
+}
+
+// loadTree reads the whole tree down to the depth limit along with the
+// content of directories at the limit, errors of directories below the
+// root are returned along with the tree.
+func loadTree(path string, opts options) (*tree.Node, error) {
+	// the tree is kept whole, there is nothing to gain by streaming it
+	opts.stream = false
+
+	w, err := newWalker(path, opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer w.Close()
+
+	root, _, err := w.Root()
+
+	if err != nil {
+		return nil, err
+	}
+
+	loadChildren(w, root.Children, opts, 1)
+
+	return root, w.Err()
+}
+
+func loadChildren(w *tree.Walker, files []*tree.Node, opts options, depth int) {
+	for _, file := range files {
+		if !file.IsDir() || file.Recursive {
+			continue
+		}
+
+		if w.Read(file) == nil && (opts.maxDepth == 0 || depth < opts.maxDepth) {
+			loadChildren(w, file.Children, opts, depth+1)
+		}
+	}
+}
+
+// snapshotEntry is an entry of a JSON snapshot, pseudo entries like
+// truncated content have no mode.
+type snapshotEntry struct {
+	jsonEntry
+	Children []snapshotEntry `json:"children"`
+}
+
+// snapshotMode tells the type of an entry of a snapshot.
+func snapshotMode(entryType string) fs.FileMode {
+	switch entryType {
+	case "directory":
+		return fs.ModeDir
+	case "link":
+		return fs.ModeSymlink
+	case "other":
+		return fs.ModeIrregular
+	default:
+		return 0
+	}
+}
+
+func readSnapshot(path string, opts options) (*tree.Node, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var root snapshotEntry
+
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	if root.Type != "directory" {
+		return nil, errors.New(path + " is not a snapshot of a directory")
+	}
+
+	return snapshotToNode(root, opts), nil
+}
+
+func snapshotToNode(snapshot snapshotEntry, opts options) *tree.Node {
+	mtime, _ := time.Parse(time.RFC3339, snapshot.Mtime)
+	node := &tree.Node{
+		Name:      snapshot.Name,
+		Mode:      snapshotMode(snapshot.Type),
+		Size:      snapshot.Size,
+		ModTime:   mtime,
+		Target:    snapshot.Target,
+		Recursive: snapshot.Recursive,
+	}
+
+	if !node.IsDir() {
+		return node
+	}
+
+	node.Loaded = true
+
+	for _, child := range snapshot.Children {
+		if child.Type == "truncated" {
+			node.Truncated = true
+
+			continue
+		}
+
+		if child.Type != "directory" && !opts.keepFiles {
+			continue
+		}
+
+		node.Children = append(node.Children, snapshotToNode(child, opts))
+	}
+
+	return node
+}
+
+// markTruncated marks directories at the depth limit changed when there
+// are changes inside, as their content is not shown.
+func markTruncated(files []*tree.Node, opts options, depth int) []*tree.Node {
+	for _, file := range files {
+		if !file.IsDir() || file.Change != tree.Unchanged {
+			continue
+		}
+
+		if opts.maxDepth != 0 && depth >= opts.maxDepth {
+			if hasDiff(file.Children) {
+				file.Change = tree.Changed
+			}
+
+			continue
+		}
+
+		markTruncated(file.Children, opts, depth+1)
+	}
+
+	return files
+}
+
+func hasDiff(files []*tree.Node) bool {
+	for _, file := range files {
+		if file.Change != tree.Unchanged || hasDiff(file.Children) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"hw1_tree/tree"
+)
+
+const diffResult = "├───a\n" +
+	"│	├───f.txt (4b) [changed, was 2b]\n" +
+	"│	└───same.txt (1b)\n" +
+	"├───gone [removed]\n" +
+	"│	└───g.txt (2b) [removed]\n" +
+	"├───kind [changed]\n" +
+	"│	└───k.txt (1b) [added]\n" +
+	"└───new [added]\n" +
+	"	└───n.txt (2b) [added]\n"
+
+func makeDiffTrees(t *testing.T) (string, string) {
+	t.Helper()
+
+	oldRoot, newRoot := t.TempDir(), t.TempDir()
+	writeFiles(t, oldRoot, map[string]string{
+		"a/f.txt":    "x\n",
+		"a/same.txt": "s",
+		"gone/g.txt": "g\n",
+		"kind":       "file",
+	})
+	writeFiles(t, newRoot, map[string]string{
+		"a/f.txt":    "xyz\n",
+		"a/same.txt": "s",
+		"kind/k.txt": "k",
+		"new/n.txt":  "n\n",
+	})
+
+	return oldRoot, newRoot
+}
+
+func TestTreeDiff(t *testing.T) {
+	oldRoot, newRoot := makeDiffTrees(t)
+
+	out := new(bytes.Buffer)
+	if err := dirTreeDiff(out, oldRoot, newRoot, options{keepFiles: true, color: colorNever}); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if result := out.String(); result != diffResult {
+		t.Errorf("results not match\nGot:\n%v\nExpected:\n%v", result, diffResult)
+	}
+}
+
+const diffHashResult = "├───a\n" +
+	"│	├───f.txt (4b) [changed, was 2b] crc32:e1ea7cd2\n" +
+	"│	└───same.txt (1b) crc32:1b0ecf0b\n" +
+	"├───gone [removed]\n" +
+	"│	└───g.txt (2b) [removed]\n" +
+	"├───kind [changed]\n" +
+	"│	└───k.txt (1b) [added] crc32:0862575d\n" +
+	"└───new [added]\n" +
+	"	└───n.txt (2b) [added] crc32:5a72bdc8\n"
+
+func TestTreeDiffHash(t *testing.T) {
+	oldRoot, newRoot := makeDiffTrees(t)
+
+	out := new(bytes.Buffer)
+	if err := dirTreeDiff(out, oldRoot, newRoot, options{keepFiles: true, color: colorNever, hash: tree.HashCRC32}); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if result := out.String(); result != diffHashResult {
+		t.Errorf("results not match\nGot:\n%v\nExpected:\n%v", result, diffHashResult)
+	}
+}
+
+func TestTreeDiffSnapshot(t *testing.T) {
+	oldRoot, newRoot := makeDiffTrees(t)
+
+	snapshot := new(bytes.Buffer)
+	if err := dirTreeIterative(snapshot, oldRoot, options{keepFiles: true, format: formatJSON}); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	snapshotPath := filepath.Join(t.TempDir(), "old.json")
+	if err := os.WriteFile(snapshotPath, snapshot.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := new(bytes.Buffer)
+	if err := dirTreeDiff(out, snapshotPath, newRoot, options{keepFiles: true, color: colorNever}); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if result := out.String(); result != diffResult {
+		t.Errorf("results not match\nGot:\n%v\nExpected:\n%v", result, diffResult)
+	}
+}
+
+func TestTreeDiffErrors(t *testing.T) {
+	if err := dirTreeDiff(new(bytes.Buffer), "testdata/missing", "testdata", options{}); err == nil {
+		t.Errorf("expected error for missing old root")
+	}
+	if err := dirTreeDiff(new(bytes.Buffer), "testdata/project/file.txt", "testdata", options{}); err == nil {
+		t.Errorf("expected error for invalid snapshot")
+	}
+}
+
+const diffDepthResult = "├───a [changed]\n" +
+	"│	└───... (2 entries)\n" +
+	"├───b [changed]\n" +
+	"│	└───... (1 entry)\n" +
+	"├───gone [removed]\n" +
+	"│	└───... (1 entry)\n" +
+	"├───kind [changed]\n" +
+	"│	└───... (1 entry)\n" +
+	"├───new [added]\n" +
+	"│	└───... (1 entry)\n" +
+	"└───same\n" +
+	"	└───... (1 entry)\n" +
+	"\n" +
+	"5 directories, 0 files, 0b\n"
+
+func TestTreeDiffDepth(t *testing.T) {
+	oldRoot, newRoot := makeDiffTrees(t)
+	// b has a change deeper than the entries under it
+	writeFiles(t, oldRoot, map[string]string{"b/deep/d.txt": "d", "same/s.txt": "s"})
+	writeFiles(t, newRoot, map[string]string{"b/deep/d.txt": "dd", "same/s.txt": "s"})
+
+	out := new(bytes.Buffer)
+	opts := options{keepFiles: true, color: colorNever, maxDepth: 1, report: true}
+	if err := dirTreeDiff(out, oldRoot, newRoot, opts); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if result := out.String(); result != diffDepthResult {
+		t.Errorf("results not match\nGot:\n%v\nExpected:\n%v", result, diffDepthResult)
+	}
+}
+
+const diffTruncatedSnapshotResult = "└───a\n" +
+	"	└───... (1 entry)\n"
+
+func TestTreeDiffTruncatedSnapshot(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"a/b/c.txt": "c"})
+
+	snapshot := new(bytes.Buffer)
+	if err := dirTreeIterative(snapshot, root, options{keepFiles: true, format: formatJSON, maxDepth: 1}); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	snapshotPath := filepath.Join(t.TempDir(), "old.json")
+	if err := os.WriteFile(snapshotPath, snapshot.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// content missing from the snapshot is not taken for added
+	out := new(bytes.Buffer)
+	if err := dirTreeDiff(out, snapshotPath, root, options{keepFiles: true, color: colorNever, maxDepth: 1}); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if result := out.String(); result != diffTruncatedSnapshotResult {
+		t.Errorf("results not match\nGot:\n%v\nExpected:\n%v", result, diffTruncatedSnapshotResult)
+	}
+}
+
+func TestTreeDiffReport(t *testing.T) {
+	oldRoot, newRoot := makeDiffTrees(t)
+
+	// removed entries are shown but not counted
+	out := new(bytes.Buffer)
+	if err := dirTreeDiff(out, oldRoot, newRoot, options{keepFiles: true, color: colorNever, report: true}); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if expected := diffResult + "\n3 directories, 4 files, 8b\n"; out.String() != expected {
+		t.Errorf("results not match\nGot:\n%v\nExpected:\n%v", out, expected)
+	}
+}
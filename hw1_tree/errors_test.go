@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const unreadableResult = "├───a\n" +
+	"│	└───f.txt (empty)\n" +
+	"├───locked [error opening dir]\n" +
+	"└───z\n" +
+	"	├───also_locked [error opening dir]\n" +
+	"	└───g.txt (empty)\n"
+
+// lockedFS cannot open some of its directories while they can still be
+// listed and stat'ed, like directories without read permission on disk,
+// which cannot be made when tests run as root.
+type lockedFS struct {
+	fstest.MapFS
+	locked map[string]bool
+}
+
+func (f lockedFS) Open(name string) (fs.File, error) {
+	if f.locked[name] {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+	}
+
+	return f.MapFS.Open(name)
+}
+
+func lockedOptions(opts options) options {
+	opts.fsys = lockedFS{
+		MapFS: fstest.MapFS{
+			"a/f.txt":                  {},
+			"locked/secret.txt":        {},
+			"z/g.txt":                  {},
+			"z/also_locked/secret.txt": {},
+		},
+		locked: map[string]bool{"locked": true, "z/also_locked": true},
+	}
+
+	return opts
+}
+
+func TestTreeLockedDirs(t *testing.T) {
+	cases := map[string]options{
+		"plain":  {keepFiles: true, color: colorNever},
+		"pruned": {keepFiles: true, color: colorNever, include: []string{"*.txt"}},
+		"stream": {keepFiles: true, color: colorNever, stream: true},
+		"jobs":   {keepFiles: true, color: colorNever, jobs: 4},
+	}
+
+	for caseName, opts := range cases {
+		for name, tree := range treeFuncs {
+			out := new(bytes.Buffer)
+			err := tree(out, "root", lockedOptions(opts))
+			if err == nil {
+				t.Errorf("%s %s: expected aggregated error", caseName, name)
+			} else if !strings.Contains(err.Error(), "open locked") || !strings.Contains(err.Error(), "open z/also_locked") {
+				t.Errorf("%s %s: expected both directories in error, got %s", caseName, name, err)
+			}
+			if result := out.String(); result != unreadableResult {
+				t.Errorf("%s %s: results not match\nGot:\n%v\nExpected:\n%v", caseName, name, result, unreadableResult)
+			}
+		}
+	}
+}
+
+func TestListRootsLockedDirs(t *testing.T) {
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	opts := lockedOptions(options{keepFiles: true, color: colorNever})
+
+	if code := listRoots([]string{"root"}, opts, out, errOut); code != exitError {
+		t.Errorf("expected exit code %d, got %d", exitError, code)
+	}
+	if result := out.String(); result != unreadableResult {
+		t.Errorf("results not match\nGot:\n%v\nExpected:\n%v", result, unreadableResult)
+	}
+	if !strings.Contains(errOut.String(), "tree: open locked") {
+		t.Errorf("error not printed, got %q", errOut)
+	}
+}
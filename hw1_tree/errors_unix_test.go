@@ -0,0 +1,52 @@
+//go:build unix
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTreeUnreadableDirs(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not checked for root")
+	}
+
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"a/f.txt":                  "",
+		"locked/secret.txt":        "",
+		"z/g.txt":                  "",
+		"z/also_locked/secret.txt": "",
+	})
+	for _, dir := range []string{"locked", "z/also_locked"} {
+		path := filepath.Join(root, dir)
+		if err := os.Chmod(path, 0); err != nil {
+			t.Fatal(err)
+		}
+		defer os.Chmod(path, 0755)
+	}
+
+	cases := map[string]options{
+		"plain":  {keepFiles: true, color: colorNever},
+		"pruned": {keepFiles: true, color: colorNever, include: []string{"*.txt"}},
+	}
+
+	for caseName, opts := range cases {
+		for name, tree := range treeFuncs {
+			out := new(bytes.Buffer)
+			err := tree(out, root, opts)
+			if err == nil {
+				t.Errorf("%s %s: expected aggregated error", caseName, name)
+			} else if !strings.Contains(err.Error(), "locked") || !strings.Contains(err.Error(), "also_locked") {
+				t.Errorf("%s %s: expected both directories in error, got %s", caseName, name, err)
+			}
+			if result := out.String(); result != unreadableResult {
+				t.Errorf("%s %s: results not match\nGot:\n%v\nExpected:\n%v", caseName, name, result, unreadableResult)
+			}
+		}
+	}
+}
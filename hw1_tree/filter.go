@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"hw1_tree/tree"
+)
+
+var typeNames = map[string]int{
+	"regular":    tree.TypeRegular,
+	"dir":        tree.TypeDir,
+	"symlink":    tree.TypeSymlink,
+	"socket":     tree.TypeSocket,
+	"device":     tree.TypeDevice,
+	"executable": tree.TypeExecutable,
+}
+
+// parseTypes reads a comma separated list of type names.
+func parseTypes(list string) (int, error) {
+	types := 0
+
+	for _, name := range strings.Split(list, ",") {
+		t, ok := typeNames[name]
+
+		if !ok {
+			return 0, fmt.Errorf("invalid type %q", name)
+		}
+
+		types |= t
+	}
+
+	return types, nil
+}
+
+// parseTime reads a moment as RFC 3339 time, a date or an age like 36h
+// or 7d counted back from now.
+func parseTime(text string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, text); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", text, time.Local); err == nil {
+		return t, nil
+	}
+
+	if days, ok := strings.CutSuffix(text, "d"); ok {
+		if d, err := time.ParseDuration(days + "h"); err == nil {
+			return now.Add(-24 * d), nil
+		}
+	} else if d, err := time.ParseDuration(text); err == nil {
+		return now.Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q, expected 2006-01-02, RFC 3339 time or age like 36h or 7d", text)
+}
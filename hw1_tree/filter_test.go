@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"hw1_tree/tree"
+)
+
+func TestParseSize(t *testing.T) {
+	cases := []struct {
+		text     string
+		expected int64
+	}{
+		{"512", 512},
+		{"19b", 19},
+		{"10K", 10240},
+		{"68.7KiB", 70349},
+		{"70.4kB", 70400},
+		{"1.5MB", 1500000},
+		{"2G", 2 << 30},
+	}
+
+	for _, c := range cases {
+		result, err := parseSize(c.text)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", c.text, err)
+		}
+		if result != c.expected {
+			t.Errorf("%s: expected %d, got %d", c.text, c.expected, result)
+		}
+	}
+
+	for _, text := range []string{"", "K", "-1", "10X", "1.2.3"} {
+		if _, err := parseSize(text); err == nil {
+			t.Errorf("%q: expected error", text)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2020, 11, 15, 9, 14, 58, 0, time.UTC)
+
+	cases := []struct {
+		text     string
+		expected time.Time
+	}{
+		{"2020-11-01T10:00:00Z", time.Date(2020, 11, 1, 10, 0, 0, 0, time.UTC)},
+		{"2020-11-01", time.Date(2020, 11, 1, 0, 0, 0, 0, time.Local)},
+		{"36h", now.Add(-36 * time.Hour)},
+		{"7d", now.Add(-7 * 24 * time.Hour)},
+	}
+
+	for _, c := range cases {
+		result, err := parseTime(c.text, now)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", c.text, err)
+		}
+		if !result.Equal(c.expected) {
+			t.Errorf("%s: expected %s, got %s", c.text, c.expected, result)
+		}
+	}
+
+	for _, text := range []string{"yesterday", "7xd"} {
+		_, err := parseTime(text, now)
+		if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("%q", text)) {
+			t.Errorf("%s: expected error naming the input, got %v", text, err)
+		}
+	}
+}
+
+func TestParseTypes(t *testing.T) {
+	types, err := parseTypes("dir,executable")
+	if err != nil || types != tree.TypeDir|tree.TypeExecutable {
+		t.Errorf("unexpected types %b, error %v", types, err)
+	}
+
+	if _, err := parseTypes("dir,pipe"); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+const sizeFilterResult = "├───css\n" +
+	"│	└───body.css (28b)\n" +
+	"└───html\n" +
+	"	└───index.html (57b)\n"
+
+func TestTreeSizeFilter(t *testing.T) {
+	checkTree(t, "testdata/static", options{keepFiles: true, color: colorNever, minSize: 20, maxSize: 1024}, sizeFilterResult)
+}
+
+func TestTreeTimeAndTypeFilters(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"old/a.txt":   "a",
+		"new/b.txt":   "b",
+		"new/run.sh":  "#!/bin/sh\n",
+		"empty/.keep": "",
+		"mixed/c.txt": "c",
+		"mixed/d.txt": "d",
+	})
+
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, name := range []string{"old/a.txt", "mixed/c.txt"} {
+		if err := os.Chtimes(filepath.Join(root, name), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chmod(filepath.Join(root, "new/run.sh"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	newer := "├───mixed\n" +
+		"│	└───d.txt (1b)\n" +
+		"└───new\n" +
+		"	├───b.txt (1b)\n" +
+		"	└───run.sh (10b)\n"
+	checkTree(t, root, options{keepFiles: true, color: colorNever, newer: old.Add(time.Hour)}, newer)
+
+	older := "├───mixed\n" +
+		"│	└───c.txt (1b)\n" +
+		"└───old\n" +
+		"	└───a.txt (1b)\n"
+	checkTree(t, root, options{keepFiles: true, color: colorNever, older: old.Add(time.Hour)}, older)
+
+	executables := "└───new\n" +
+		"	└───run.sh (10b)\n"
+	checkTree(t, root, options{keepFiles: true, color: colorNever, types: tree.TypeExecutable}, executables)
+
+	dirs := "├───empty\n" +
+		"├───mixed\n" +
+		"├───new\n" +
+		"└───old\n"
+	checkTree(t, root, options{keepFiles: true, color: colorNever, types: tree.TypeDir}, dirs)
+}
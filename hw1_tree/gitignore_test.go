@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t testing.TB, root string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+const gitIgnoreResult = "├───\033[1;32m.gitignore\033[0;m \033[1;35m(31b)\033[0;m\n" +
+	"├───\033[1;32mkeep.log\033[0;m \033[1;35m(empty)\033[0;m\n" +
+	"├───\033[1;32mmain.go\033[0;m \033[1;35m(empty)\033[0;m\n" +
+	"└───\033[1;34msrc\033[0;m\n" +
+	"	├───\033[1;32m.gitignore\033[0;m \033[1;35m(21b)\033[0;m\n" +
+	"	├───\033[1;32mb.go\033[0;m \033[1;35m(empty)\033[0;m\n" +
+	"	├───\033[1;32mimportant.log\033[0;m \033[1;35m(empty)\033[0;m\n" +
+	"	└───\033[1;34mvendor\033[0;m\n" +
+	"		└───\033[1;32mx.go\033[0;m \033[1;35m(empty)\033[0;m\n"
+
+func TestTreeGitIgnore(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".git/HEAD":         "",
+		".gitignore":        "*.log\nbuild/\n!keep.log\n/vendor\n",
+		"app.log":           "",
+		"keep.log":          "",
+		"main.go":           "",
+		"build/out.bin":     "",
+		"vendor/lib.go":     "",
+		"src/.gitignore":    "*.tmp\n!important.log\n",
+		"src/a.tmp":         "",
+		"src/b.go":          "",
+		"src/debug.log":     "",
+		"src/important.log": "",
+		"src/vendor/x.go":   "",
+	})
+
+	checkTree(t, root, options{keepFiles: true, showHidden: true, gitIgnore: true}, gitIgnoreResult)
+}
+
+const gitIgnoreArchiveResult = "├───.gitignore (6b)\n" +
+	"├───main.go (empty)\n" +
+	"└───src\n" +
+	"	├───.gitignore (6b)\n" +
+	"	└───b.go (empty)\n"
+
+func TestTreeGitIgnoreArchive(t *testing.T) {
+	root := t.TempDir()
+	// rules next to the archive are not the archive's, its own are
+	writeFiles(t, root, map[string]string{".gitignore": "*.go\n"})
+
+	path := filepath.Join(root, "files.zip")
+	writeZip(t, path, map[string]string{
+		".gitignore":     "*.log\n",
+		"app.log":        "",
+		"main.go":        "",
+		"src/.gitignore": "*.tmp\n",
+		"src/a.tmp":      "",
+		"src/b.go":       "",
+	})
+
+	checkArchive(t, path, options{keepFiles: true, showHidden: true, color: colorNever, gitIgnore: true}, gitIgnoreArchiveResult)
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+)
+
+const grepResult = "├───css\n" +
+	"│	└───body.css (28b) [1 matching line]\n" +
+	"└───js\n" +
+	"	└───site.js (10b) [1 matching line]\n"
+
+func TestTreeGrep(t *testing.T) {
+	opts := options{keepFiles: true, color: colorNever, grep: regexp.MustCompile(`^body|var`)}
+
+	checkTree(t, "testdata/static", opts, grepResult)
+}
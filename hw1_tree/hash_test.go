@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+
+	"hw1_tree/tree"
+)
+
+const hashResult = "├───a_lorem\n" +
+	"│	├───dolor.txt (empty) crc32:00000000\n" +
+	"│	├───gopher.png (70372b) crc32:26524903\n" +
+	"│	└───ipsum\n" +
+	"│		└───gopher.png (70372b) crc32:26524903\n" +
+	"├───css\n" +
+	"│	└───body.css (28b) crc32:5f579153\n" +
+	"└───empty.txt (empty) crc32:00000000\n"
+
+func TestTreeHash(t *testing.T) {
+	for _, jobs := range []int{0, 3} {
+		opts := options{keepFiles: true, color: colorNever, hash: tree.HashCRC32, jobs: jobs, exclude: []string{"html", "js", "z_lorem"}}
+
+		checkTree(t, "testdata/static", opts, hashResult)
+	}
+}
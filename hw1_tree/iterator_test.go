@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTreeStream(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{"sub/inner.txt": "x", "sub/deeper/x": ""}
+
+	// more than a chunk of 1024 entries read at once, so the last entry
+	// is found across reads
+	count := 1100
+	for i := 0; i < count; i++ {
+		files[fmt.Sprintf("f%04d.txt", i)] = ""
+	}
+	writeFiles(t, root, files)
+
+	for name, tree := range treeFuncs {
+		out := new(bytes.Buffer)
+		err := tree(out, root, options{keepFiles: true, color: colorNever, stream: true, maxDepth: 2})
+		if err != nil {
+			t.Fatalf("%s: unexpected error %s", name, err)
+		}
+
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		topLevel := []string{}
+		for _, line := range lines {
+			if strings.HasPrefix(line, "├───") || strings.HasPrefix(line, "└───") {
+				topLevel = append(topLevel, line)
+			}
+		}
+
+		if len(topLevel) != count+1 {
+			t.Fatalf("%s: expected %d entries, got %d", name, count+1, len(topLevel))
+		}
+
+		for i, line := range topLevel {
+			if isLast := strings.HasPrefix(line, "└───"); isLast != (i == len(topLevel)-1) {
+				t.Errorf("%s: wrong connector of entry %d %q", name, i, line)
+			}
+		}
+
+		result := out.String()
+		if !strings.Contains(result, "───inner.txt (1b)\n") || !strings.Contains(result, "───deeper\n") || !strings.Contains(result, "└───... (1 entry)\n") {
+			t.Errorf("%s: subdirectory is not listed properly\n%s", name, result)
+		}
+	}
+}
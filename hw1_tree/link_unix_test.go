@@ -0,0 +1,79 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func makeLinksTree(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"a/f.txt":      "hi\n",
+		"a/b/.keep":    "",
+		"c/.keep":      "",
+		"z/g.txt":      "hello\n",
+		"z/deep/.keep": "",
+	})
+
+	links := map[string]string{
+		"a/b/up": "../..",
+		"c/toz":  "../z",
+		"c/toa":  "../a",
+		"broken": "nowhere",
+		"flink":  "a/f.txt",
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(root, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return root
+}
+
+const showLinksResult = "├───a\n" +
+	"│	├───b\n" +
+	"│	│	└───up -> ../.. (5b)\n" +
+	"│	└───f.txt (3b)\n" +
+	"├───broken -> nowhere (7b)\n" +
+	"├───c\n" +
+	"│	├───toa -> ../a (4b)\n" +
+	"│	└───toz -> ../z (4b)\n" +
+	"├───flink -> a/f.txt (7b)\n" +
+	"└───z\n" +
+	"	├───deep\n" +
+	"	└───g.txt (6b)\n"
+
+func TestTreeShowLinks(t *testing.T) {
+	root := makeLinksTree(t)
+
+	checkTree(t, root, options{keepFiles: true, color: colorNever, showLinks: true, exclude: []string{".keep"}}, showLinksResult)
+}
+
+// links to directories listed before are not followed, but toz points to
+// a directory which comes later, so it is listed twice
+const followLinksResult = "├───a\n" +
+	"│	├───b\n" +
+	"│	│	└───up -> ../.. [recursive, not followed]\n" +
+	"│	└───f.txt (3b)\n" +
+	"├───broken -> nowhere (7b)\n" +
+	"├───c\n" +
+	"│	├───toa -> ../a [recursive, not followed]\n" +
+	"│	└───toz -> ../z\n" +
+	"│		├───deep\n" +
+	"│		└───g.txt (6b)\n" +
+	"├───flink -> a/f.txt (7b)\n" +
+	"└───z\n" +
+	"	├───deep\n" +
+	"	└───g.txt (6b)\n"
+
+func TestTreeFollowLinks(t *testing.T) {
+	root := makeLinksTree(t)
+
+	checkTree(t, root, options{keepFiles: true, color: colorNever, followLinks: true, exclude: []string{".keep"}}, followLinksResult)
+}
@@ -1,203 +1,266 @@
 package main
 
 import (
-	"fmt"
+	"errors"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
-	"sort"
-	"strings"
+	"regexp"
+	"time"
+
+	"hw1_tree/tree"
 )
 
+type options struct {
+	keepFiles bool
+	// showHidden includes entries whose names start with a dot, they are
+	// skipped by default
+	showHidden bool
+	// include keeps only files whose name matches one of the patterns,
+	// exclude drops files and directories matching any of them
+	include []string
+	exclude []string
+	// grep keeps only files with lines matching the pattern
+	grep *regexp.Regexp
+	// minSize, maxSize, newer and older select files by size and
+	// modification time, zero values do not limit, types is a combination
+	// of tree.TypeRegular, tree.TypeDir, ..., zero selects all
+	minSize int64
+	maxSize int64
+	newer   time.Time
+	older   time.Time
+	types   int
+	// maxDepth limits how deep the tree is descended, 0 means no limit
+	maxDepth int
+	// color is one of colorAlways, colorAuto, colorNever, colors
+	// overrides the default color scheme
+	color  int
+	colors *colorScheme
+	// du sums up sizes of directories content
+	du bool
+	// sortBy is one of sortByName, sortBySize, ..., dirsFirst and reverse
+	// are applied on top of it
+	sortBy    int
+	dirsFirst bool
+	reverse   bool
+	// sizeUnits is one of sizeBytes, sizeBinary, sizeSI, allocated
+	// switches from apparent sizes to sizes of allocated blocks
+	sizeUnits int
+	allocated bool
+	// showLinks displays targets of symbolic links, followLinks descends
+	// into linked directories
+	showLinks   bool
+	followLinks bool
+	// jobs is the number of workers reading directories ahead of the
+	// traversal and hashing files
+	jobs int
+	// hash is one of tree.HashNone, tree.HashSHA256, ...
+	hash int
+	// fsys is an archive browsed instead of the OS file system, the path
+	// of the traversal is its root
+	fsys fs.FS
+	// stream reads directories in chunks and lists them unsorted
+	stream bool
+	// watch is one of watchNone, watchRender, watchEvents
+	watch int
+	// diffBase is the directory or JSON snapshot the roots are compared to
+	diffBase string
+	// format selects the output renderer, htmlBase prefixes links to
+	// files in HTML output
+	format   int
+	htmlBase string
+	// columns selects metadata shown with every entry, e.g.
+	// columnPerms|columnTime
+	columns int
+	// charset is one of charsetUnicode, charsetASCII, charsetCompact,
+	// indent is the width of a level, 0 keeps the default of the charset
+	charset int
+	indent  int
+	// report adds totals after the tree, reportTypes counts files by
+	// extension in it
+	report      bool
+	reportTypes bool
+	// gitIgnore skips entries ignored by .gitignore files
+	gitIgnore bool
+}
+
 func main() {
-	out := os.Stdout
-	if !(len(os.Args) == 2 || len(os.Args) == 3) {
-		panic("usage go run main.go . [-f]")
-	}
-	path := os.Args[1]
-	keepFiles := len(os.Args) == 3 && os.Args[2] == "-f"
-	// err := dirTree(out, path, keepFiles)
-	err := dirTreeIterative(out, path, keepFiles)
-	if err != nil {
-		panic(err.Error())
-	}
+	// run lists roots with dirTreeIterative, dirTreeRecursive gives the
+	// same output
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
 }
 
 func dirTree(out io.Writer, path string, keepFiles bool) error {
-	return dirTreeRecursiveInner(out, path, keepFiles, []string{})
+	return dirTreeRecursive(out, path, options{keepFiles: keepFiles})
 }
 
-func readDir(path string, keepFiles bool) ([]os.FileInfo, error) {
-	folder, err := os.Open(path)
+func dirTreeRecursive(out io.Writer, path string, opts options) error {
+	w, err := newWalker(path, opts)
 
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	files, err := folder.Readdir(-1)
+	defer w.Close()
+
+	root, files, err := w.Root()
 
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	folder.Close()
+	r := newRenderer(out, path, root, opts)
 
-	if !keepFiles {
-		files = filterOutFiles(files)
-	}
+	dirTreeRecursiveInner(r, w, path, files, opts, 1)
 
-	sortFilesByName(files)
+	return errors.Join(r.finish(), w.Err())
+}
 
-	return files, nil
+// newWalker prepares a single traversal from the root, either of the
+// browsed archive or of the OS file system. The walker has to be closed
+// when the traversal is done.
+func newWalker(path string, opts options) (*tree.Walker, error) {
+	if opts.fsys != nil {
+		return tree.NewWalker(opts.fsys, ".", treeOptions(opts))
+	}
+
+	return tree.NewWalker(tree.Host, path, treeOptions(opts))
 }
 
-func sortFilesByName(files []os.FileInfo) {
-	sort.SliceStable(files, func(i, j int) bool {
-		return files[i].Name() < files[j].Name()
-	})
+// treeOptions selects entries of the traversal the way command options
+// ask for.
+func treeOptions(opts options) tree.Options {
+	return tree.Options{
+		Files:       opts.keepFiles,
+		MaxDepth:    opts.maxDepth,
+		Include:     opts.include,
+		Exclude:     opts.exclude,
+		Hidden:      opts.showHidden,
+		Less:        lessFunc(opts),
+		Grep:        opts.grep,
+		MinSize:     opts.minSize,
+		MaxSize:     opts.maxSize,
+		Newer:       opts.newer,
+		Older:       opts.older,
+		Types:       opts.types,
+		GitIgnore:   opts.gitIgnore,
+		DiskUsage:   opts.du,
+		Allocated:   opts.allocated,
+		Links:       opts.showLinks,
+		FollowLinks: opts.followLinks,
+		Jobs:        opts.jobs,
+		Hash:        opts.hash,
+		Stream:      opts.stream,
+	}
 }
 
-func filterOutFiles(files []os.FileInfo) []os.FileInfo {
-	folders := make([]os.FileInfo, 0)
+func dirTreeRecursiveInner(r renderer, w *tree.Walker, path string, files tree.Iterator, opts options, depth int) {
+	defer files.Close()
+
+	for {
+		file, isLast, ok := files.Next()
+
+		if !ok {
+			return
+		}
+
+		filePath := filepath.Join(path, file.Name)
 
-	for _, file := range files {
 		if !file.IsDir() {
+			r.file(filePath, file, isLast)
+
 			continue
 		}
 
-		folders = append(folders, file)
-	}
+		dirFiles, err := w.Open(file)
 
-	return folders
-}
+		r.openDir(filePath, file, isLast)
 
-func printOutLine(out io.Writer, file os.FileInfo, prefix []string, isLast bool) {
-	fmt.Fprintf(out, strings.Join(prefix, ""))
+		if err == nil {
+			if opts.maxDepth != 0 && depth >= opts.maxDepth {
+				if count := dirFiles.Count(); count != 0 {
+					r.truncated(count)
+				}
 
-	if isLast {
-		fmt.Fprintf(out, "└───")
-	} else {
-		fmt.Fprintf(out, "├───")
-	}
+				dirFiles.Close()
+			} else {
+				dirTreeRecursiveInner(r, w, filePath, dirFiles, opts, depth+1)
+			}
+		}
 
-	if file.IsDir() {
-		fmt.Fprintf(out, "\033[1;34m")
-	} else {
-		fmt.Fprintf(out, "\033[1;32m")
+		r.closeDir()
 	}
-
-	fmt.Fprintf(out, "%s%s", file.Name(), "\033[0;m")
 }
 
-func printOutFileSize(out io.Writer, file os.FileInfo) {
-	fileSize := file.Size()
-
-	fmt.Fprintf(out, " \033[1;35m")
+func dirTreeIterative(out io.Writer, root string, opts options) error {
+	w, err := newWalker(root, opts)
 
-	if fileSize == 0 {
-		fmt.Fprintf(out, "(empty)")
-	} else {
-		fmt.Fprintf(out, "(%db)", fileSize)
+	if err != nil {
+		return err
 	}
 
-	fmt.Fprintf(out, "\033[0;m")
-}
+	defer w.Close()
 
-func dirTreeIterative(out io.Writer, root string, keepFiles bool) error {
 	path := []string{root}
-	prefix := []string{""}
 
-	rootFolderFiles, err := readDir(root, keepFiles)
+	rootDir, rootFiles, err := w.Root()
 
 	if err != nil {
 		return err
 	}
 
-	files := [][]os.FileInfo{rootFolderFiles}
+	r := newRenderer(out, root, rootDir, opts)
+	files := []tree.Iterator{rootFiles}
 
 	for len(files) != 0 {
 		curDirFiles := files[len(files)-1]
+		file, isLast, ok := curDirFiles.Next()
 
-		if len(curDirFiles) == 0 {
+		if !ok {
+			curDirFiles.Close()
 			files = files[:len(files)-1]
-			prefix = prefix[:len(prefix)-1]
 			path = path[:len(path)-1]
 
-			continue
-		}
-
-		for len(curDirFiles) != 0 {
-			file := curDirFiles[0]
-			curDirFiles = curDirFiles[1:]
-			files[len(files)-1] = curDirFiles
-
-			isLast := len(curDirFiles) == 0
-
-			printOutLine(out, file, prefix, isLast)
-
-			if !file.IsDir() {
-				printOutFileSize(out, file)
+			if len(files) != 0 {
+				r.closeDir()
 			}
 
-			fmt.Fprintf(out, "\n")
-
-			if file.IsDir() {
-				path = append(path, file.Name())
-
-				dirFiles, err := readDir(filepath.Join(path...), keepFiles)
-
-				if err != nil {
-					return err
-				}
+			continue
+		}
 
-				files = append(files, dirFiles)
+		filePath := filepath.Join(append(path, file.Name)...)
 
-				if isLast {
-					prefix = append(prefix, "\t")
-				} else {
-					prefix = append(prefix, "│\t")
-				}
+		if !file.IsDir() {
+			r.file(filePath, file, isLast)
 
-				break
-			}
+			continue
 		}
-	}
-
-	return nil
-}
 
-func dirTreeRecursiveInner(out io.Writer, path string, keepFiles bool, prefix []string) error {
-	files, err := readDir(path, keepFiles)
-
-	if err != nil {
-		return err
-	}
+		dirFiles, err := w.Open(file)
 
-	for index, file := range files {
-		name := file.Name()
-		isDir := file.IsDir()
-		isLast := index == len(files)-1
+		r.openDir(filePath, file, isLast)
 
-		printOutLine(out, file, prefix, isLast)
+		if err != nil {
+			r.closeDir()
 
-		if !isDir {
-			printOutFileSize(out, file)
+			continue
 		}
 
-		fmt.Fprintf(out, "\n")
-
-		if isDir {
-			parentPath := filepath.Join(path, name)
-
-			if isLast {
-				dirTreeRecursiveInner(out, parentPath, keepFiles, append(prefix, "\t"))
-
-				continue
+		if opts.maxDepth != 0 && len(files) >= opts.maxDepth {
+			if count := dirFiles.Count(); count != 0 {
+				r.truncated(count)
 			}
 
-			dirTreeRecursiveInner(out, parentPath, keepFiles, append(prefix, "│\t"))
+			dirFiles.Close()
+			r.closeDir()
+
+			continue
 		}
+
+		path = append(path, file.Name)
+		files = append(files, dirFiles)
 	}
 
-	return nil
+	return errors.Join(r.finish(), w.Err())
 }
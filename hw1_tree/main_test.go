@@ -2,7 +2,10 @@ package main
 
 import (
 	"bytes"
+	"io"
+	"os"
 	"testing"
+	"time"
 )
 
 const testFullResult = "├───\033[1;34mproject\033[0;m\n" +
@@ -49,7 +52,7 @@ func TestRecursiveTreeFull(t *testing.T) {
 
 func TestIterativeTreeFull(t *testing.T) {
 	out := new(bytes.Buffer)
-	err := dirTreeIterative(out, "testdata", true)
+	err := dirTreeIterative(out, "testdata", options{keepFiles: true})
 	if err != nil {
 		t.Errorf("test for OK Failed - error")
 	}
@@ -86,7 +89,7 @@ func TestRecursiveTreeDir(t *testing.T) {
 
 func TestIterativeTreeDir(t *testing.T) {
 	out := new(bytes.Buffer)
-	err := dirTreeIterative(out, "testdata", false)
+	err := dirTreeIterative(out, "testdata", options{})
 	if err != nil {
 		t.Errorf("test for OK Failed - error")
 	}
@@ -113,7 +116,7 @@ func TestRecursiveFilesOnly(t *testing.T) {
 
 func TestIterativeFilesOnly(t *testing.T) {
 	out := new(bytes.Buffer)
-	err := dirTreeIterative(out, "testdata/project", true)
+	err := dirTreeIterative(out, "testdata/project", options{keepFiles: true})
 	if err != nil {
 		t.Errorf("test for OK Failed - error")
 	}
@@ -123,12 +126,123 @@ func TestIterativeFilesOnly(t *testing.T) {
 	}
 }
 
+var treeFuncs = map[string]func(out io.Writer, path string, opts options) error{
+	"recursive": dirTreeRecursive,
+	"iterative": dirTreeIterative,
+}
+
+type testFileInfo struct {
+	name    string
+	size    int64
+	mode    os.FileMode
+	modTime time.Time
+}
+
+func (info testFileInfo) Name() string       { return info.name }
+func (info testFileInfo) Size() int64        { return info.size }
+func (info testFileInfo) Mode() os.FileMode  { return info.mode }
+func (info testFileInfo) ModTime() time.Time { return info.modTime }
+func (info testFileInfo) IsDir() bool        { return info.mode.IsDir() }
+func (info testFileInfo) Sys() interface{}   { return nil }
+
+func checkTree(t *testing.T, path string, opts options, expected string) {
+	t.Helper()
+
+	for name, tree := range treeFuncs {
+		out := new(bytes.Buffer)
+		err := tree(out, path, opts)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", name, err)
+		}
+		result := out.String()
+		if result != expected {
+			t.Errorf("%s: results not match\nGot:\n%v\nExpected:\n%v", name, result, expected)
+		}
+	}
+}
+
+const includePatternsResult = "└───\033[1;34mstatic\033[0;m\n" +
+	"	├───\033[1;34mcss\033[0;m\n" +
+	"	│	└───\033[1;32mbody.css\033[0;m \033[1;35m(28b)\033[0;m\n" +
+	"	└───\033[1;34mjs\033[0;m\n" +
+	"		└───\033[1;32msite.js\033[0;m \033[1;35m(10b)\033[0;m\n"
+
+func TestTreeIncludePatterns(t *testing.T) {
+	checkTree(t, "testdata", options{keepFiles: true, include: []string{"*.css", "*.js"}}, includePatternsResult)
+}
+
+const includePatternsDirResult = "└───\033[1;34mstatic\033[0;m\n" +
+	"	├───\033[1;34mcss\033[0;m\n" +
+	"	└───\033[1;34mjs\033[0;m\n"
+
+func TestTreeIncludePatternsDir(t *testing.T) {
+	checkTree(t, "testdata", options{include: []string{"*.css", "*.js"}}, includePatternsDirResult)
+}
+
+const excludePatternsResult = "├───\033[1;34mproject\033[0;m\n" +
+	"│	└───\033[1;32mfile.txt\033[0;m \033[1;35m(19b)\033[0;m\n" +
+	"├───\033[1;34mzline\033[0;m\n" +
+	"│	├───\033[1;32mempty.txt\033[0;m \033[1;35m(empty)\033[0;m\n" +
+	"│	└───\033[1;34mlorem\033[0;m\n" +
+	"│		└───\033[1;32mdolor.txt\033[0;m \033[1;35m(empty)\033[0;m\n" +
+	"└───\033[1;32mzzfile.txt\033[0;m \033[1;35m(empty)\033[0;m\n"
+
+func TestTreeExcludePatterns(t *testing.T) {
+	checkTree(t, "testdata", options{keepFiles: true, exclude: []string{"*.png", "static", "ipsum"}}, excludePatternsResult)
+}
+
+const depthLimitResult = "├───\033[1;34mproject\033[0;m\n" +
+	"├───\033[1;34mstatic\033[0;m\n" +
+	"│	└───... (5 entries)\n" +
+	"└───\033[1;34mzline\033[0;m\n" +
+	"	└───... (1 entry)\n"
+
+func TestTreeDepthLimit(t *testing.T) {
+	checkTree(t, "testdata", options{maxDepth: 1}, depthLimitResult)
+}
+
+const depthLimitFullResult = "├───\033[1;32mfile.txt\033[0;m \033[1;35m(19b)\033[0;m\n" +
+	"└───\033[1;32mgopher.png\033[0;m \033[1;35m(70372b)\033[0;m\n"
+
+func TestTreeDepthLimitNothingTruncated(t *testing.T) {
+	checkTree(t, "testdata/project", options{keepFiles: true, maxDepth: 1}, depthLimitFullResult)
+}
+
+const diskUsageResult = "├───static (281583b, 10 files)\n" +
+	"│	├───a_lorem (140744b, 3 files)\n" +
+	"│	│	└───ipsum (70372b, 1 file)\n" +
+	"│	├───z_lorem (140744b, 3 files)\n" +
+	"│	│	└───ipsum (70372b, 1 file)\n" +
+	"│	├───html (57b, 1 file)\n" +
+	"│	├───css (28b, 1 file)\n" +
+	"│	└───js (10b, 1 file)\n" +
+	"├───zline (140744b, 4 files)\n" +
+	"│	└───lorem (140744b, 3 files)\n" +
+	"│		└───ipsum (70372b, 1 file)\n" +
+	"└───project (70391b, 2 files)\n"
+
+func TestTreeDiskUsage(t *testing.T) {
+	checkTree(t, "testdata", options{du: true, sortBy: sortBySize, color: colorNever}, diskUsageResult)
+}
+
+const diskUsageFilteredResult = "├───css (28b, 1 file)\n" +
+	"│	└───body.css (28b)\n" +
+	"├───empty.txt (empty)\n" +
+	"└───z_lorem (empty, 1 file)\n" +
+	"	└───dolor.txt (empty)\n"
+
+func TestTreeDiskUsageFiltered(t *testing.T) {
+	opts := options{keepFiles: true, du: true, color: colorNever, sortBy: sortBySize, include: []string{"*.txt", "*.css"}, exclude: []string{"a_lorem"}}
+
+	checkTree(t, "testdata/static", opts, diskUsageFilteredResult)
+}
+
 func BenchmarkRecursive(t *testing.B) {
 	out := new(bytes.Buffer)
 
 	for i := 0; i < t.N; i++ {
 		out.Reset()
-		err := dirTreeRecursiveInner(out, "testdata/project", true, []string{})
+		err := dirTreeRecursive(out, "testdata/project", options{keepFiles: true})
 
 		if err != nil {
 			t.Errorf(`expected nil, got error '%s'`, err)
@@ -141,10 +255,24 @@ func BenchmarkIterative(t *testing.B) {
 
 	for i := 0; i < t.N; i++ {
 		out.Reset()
-		err := dirTreeIterative(out, "testdata/project", true)
+		err := dirTreeIterative(out, "testdata/project", options{keepFiles: true})
 
 		if err != nil {
 			t.Errorf(`expected nil, got error '%s'`, err)
 		}
 	}
 }
+
+const hiddenResult = "├───.env (4b)\n" +
+	"└───src\n" +
+	"	├───.cache\n" +
+	"	│	└───a.o (1b)\n" +
+	"	└───main.go (1b)\n"
+
+func TestTreeHidden(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{".env": "A=1\n", "src/main.go": "m", "src/.cache/a.o": "a"})
+
+	checkTree(t, root, options{keepFiles: true, color: colorNever}, "└───src\n\t└───main.go (1b)\n")
+	checkTree(t, root, options{keepFiles: true, color: colorNever, showHidden: true}, hiddenResult)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// columns of metadata shown before names, combined with bitwise or
+const (
+	columnInode = 1 << iota
+	columnPerms
+	columnUser
+	columnGroup
+	columnTime
+	// columnFullPath shows the path from the root instead of the name
+	columnFullPath
+)
+
+const columnTimeLayout = "2006-01-02 15:04"
+
+// owners resolves user and group ids into names, every id is looked up
+// once per traversal.
+type owners struct {
+	users  map[uint32]string
+	groups map[uint32]string
+}
+
+func newOwners() *owners {
+	return &owners{users: make(map[uint32]string), groups: make(map[uint32]string)}
+}
+
+// fileMeta holds optional metadata of an entry, columns which were not
+// requested stay zero.
+type fileMeta struct {
+	inode uint64
+	user  string
+	group string
+	path  string
+}
+
+func newFileMeta(path string, file os.FileInfo, columns int, o *owners) fileMeta {
+	meta := fileMeta{}
+
+	if columns&columnInode != 0 {
+		meta.inode, _ = fileInode(file)
+	}
+
+	if columns&(columnUser|columnGroup) != 0 {
+		if uid, gid, ok := fileOwner(file); ok {
+			if columns&columnUser != 0 {
+				meta.user = o.user(uid)
+			}
+
+			if columns&columnGroup != 0 {
+				meta.group = o.group(gid)
+			}
+		}
+	}
+
+	if columns&columnFullPath != 0 {
+		meta.path = path
+	}
+
+	return meta
+}
+
+// formatColumns makes the text shown in brackets before the name, values
+// unknown on this system are shown as a dash.
+func formatColumns(file os.FileInfo, meta fileMeta, columns int) string {
+	parts := []string{}
+
+	if columns&columnInode != 0 {
+		if meta.inode == 0 {
+			parts = append(parts, fmt.Sprintf("%8s", "-"))
+		} else {
+			parts = append(parts, fmt.Sprintf("%8d", meta.inode))
+		}
+	}
+
+	if columns&columnPerms != 0 {
+		parts = append(parts, file.Mode().String())
+	}
+
+	if columns&columnUser != 0 {
+		parts = append(parts, fmt.Sprintf("%-8s", orDash(meta.user)))
+	}
+
+	if columns&columnGroup != 0 {
+		parts = append(parts, fmt.Sprintf("%-8s", orDash(meta.group)))
+	}
+
+	if columns&columnTime != 0 {
+		parts = append(parts, file.ModTime().Format(columnTimeLayout))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
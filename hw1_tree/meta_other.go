@@ -0,0 +1,23 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// fileInode reports nothing where stat data has no inode numbers.
+func fileInode(info os.FileInfo) (uint64, bool) {
+	return 0, false
+}
+
+// fileOwner reports nothing where stat data has no owner ids.
+func fileOwner(info os.FileInfo) (uint32, uint32, bool) {
+	return 0, 0, false
+}
+
+func (o *owners) user(uid uint32) string {
+	return ""
+}
+
+func (o *owners) group(gid uint32) string {
+	return ""
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestFormatColumns(t *testing.T) {
+	file := testFileInfo{name: "a.txt", mode: 0640, modTime: time.Date(2020, 11, 15, 9, 14, 58, 0, time.Local)}
+	meta := fileMeta{inode: 42, user: "gopher"}
+
+	cases := []struct {
+		columns  int
+		expected string
+	}{
+		{columnPerms, "-rw-r-----"},
+		{columnTime, "2020-11-15 09:14"},
+		{columnInode | columnUser | columnGroup, "      42 gopher   -       "},
+		{columnPerms | columnTime, "-rw-r----- 2020-11-15 09:14"},
+	}
+
+	for _, c := range cases {
+		if result := formatColumns(file, meta, c.columns); result != c.expected {
+			t.Errorf("%b: expected %q, got %q", c.columns, c.expected, result)
+		}
+	}
+
+	dir := testFileInfo{name: "b", mode: os.ModeDir | 0755}
+	if result := formatColumns(dir, fileMeta{}, columnInode|columnPerms); result != "       - drwxr-xr-x" {
+		t.Errorf("unexpected columns of directory %q", result)
+	}
+}
+
+const fullPathResult = "├───testdata/static/css\n" +
+	"│	└───testdata/static/css/body.css (28b)\n" +
+	"└───testdata/static/html\n" +
+	"	└───testdata/static/html/index.html (57b)\n"
+
+func TestTreeFullPath(t *testing.T) {
+	opts := options{keepFiles: true, color: colorNever, columns: columnFullPath, include: []string{"*.css", "*.html"}}
+
+	checkTree(t, "testdata/static", opts, fullPathResult)
+}
@@ -0,0 +1,67 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+func fileInode(info os.FileInfo) (uint64, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+
+	if !ok {
+		return 0, false
+	}
+
+	return uint64(stat.Ino), true
+}
+
+func fileOwner(info os.FileInfo) (uint32, uint32, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+
+	if !ok {
+		return 0, 0, false
+	}
+
+	return stat.Uid, stat.Gid, true
+}
+
+// user returns the name of the user, or the id itself when it has no name.
+func (o *owners) user(uid uint32) string {
+	if name, ok := o.users[uid]; ok {
+		return name
+	}
+
+	id := strconv.FormatUint(uint64(uid), 10)
+	name := id
+
+	if u, err := user.LookupId(id); err == nil {
+		name = u.Username
+	}
+
+	o.users[uid] = name
+
+	return name
+}
+
+// group returns the name of the group, or the id itself when it has no
+// name.
+func (o *owners) group(gid uint32) string {
+	if name, ok := o.groups[gid]; ok {
+		return name
+	}
+
+	id := strconv.FormatUint(uint64(gid), 10)
+	name := id
+
+	if g, err := user.LookupGroupId(id); err == nil {
+		name = g.Name
+	}
+
+	o.groups[gid] = name
+
+	return name
+}
@@ -0,0 +1,38 @@
+//go:build unix
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestTreeOwnerColumns(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"a.txt": "a"})
+
+	current, err := user.Current()
+	if err != nil {
+		t.Skip("current user is unknown")
+	}
+
+	info, err := os.Stat(filepath.Join(root, "a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := fmt.Sprintf("└───[%8d %-8s] a.txt (1b)\n", info.Sys().(*syscall.Stat_t).Ino, current.Username)
+
+	out := new(bytes.Buffer)
+	if err := dirTreeIterative(out, root, options{keepFiles: true, color: colorNever, columns: columnInode | columnUser}); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if result := out.String(); result != expected {
+		t.Errorf("results not match\nGot:\n%v\nExpected:\n%v", result, expected)
+	}
+}
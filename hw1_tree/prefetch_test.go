@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestTreePrefetch(t *testing.T) {
+	checkTree(t, "testdata", options{keepFiles: true, jobs: 4}, testFullResult)
+	checkTree(t, "testdata", options{jobs: 4}, testDirResult)
+	checkTree(t, "testdata", options{jobs: 4, maxDepth: 1}, depthLimitResult)
+	checkTree(t, "testdata", options{jobs: 2, du: true, sortBy: sortBySize, color: colorNever}, diskUsageResult)
+}
+
+func makeWideTree(b *testing.B, width, depth int) string {
+	b.Helper()
+
+	files := make(map[string]string)
+	var fill func(prefix string, level int)
+	fill = func(prefix string, level int) {
+		for i := 0; i < width; i++ {
+			name := filepath.Join(prefix, fmt.Sprintf("dir%d", i))
+			files[filepath.Join(name, "file.txt")] = "content"
+			if level < depth {
+				fill(name, level+1)
+			}
+		}
+	}
+	fill("", 1)
+
+	root := b.TempDir()
+	writeFiles(b, root, files)
+
+	return root
+}
+
+func benchmarkWideTree(b *testing.B, jobs int) {
+	root := makeWideTree(b, 8, 3)
+	out := new(bytes.Buffer)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		out.Reset()
+		err := dirTreeIterative(out, root, options{keepFiles: true, jobs: jobs})
+
+		if err != nil {
+			b.Errorf(`expected nil, got error '%s'`, err)
+		}
+	}
+}
+
+func BenchmarkIterativeWideTree(b *testing.B) {
+	benchmarkWideTree(b, 1)
+}
+
+func BenchmarkIterativeWideTreePrefetch(b *testing.B) {
+	benchmarkWideTree(b, 8)
+}
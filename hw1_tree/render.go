@@ -0,0 +1,259 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"hw1_tree/tree"
+)
+
+const (
+	formatText = iota
+	formatJSON
+	formatXML
+	formatHTML
+)
+
+// renderer receives entries in traversal order. Every openDir call is
+// paired with closeDir, the content of the directory comes in between.
+type renderer interface {
+	start(root string, dir *tree.Node)
+	file(path string, file *tree.Node, isLast bool)
+	openDir(path string, dir *tree.Node, isLast bool)
+	closeDir()
+	// truncated reports directory content skipped because of depth limit
+	truncated(count int)
+	// report receives totals of the tree before finish when requested
+	report(t totals)
+	finish() error
+}
+
+func newRenderer(out io.Writer, root string, rootDir *tree.Node, opts options) renderer {
+	var r renderer
+
+	switch opts.format {
+	case formatJSON:
+		r = &jsonRenderer{out: out, columns: opts.columns, owners: newOwners()}
+	case formatXML:
+		r = newXMLRenderer(out, opts.columns)
+	case formatHTML:
+		r = &htmlRenderer{
+			out:       out,
+			base:      opts.htmlBase,
+			sizeUnits: opts.sizeUnits,
+			keepFiles: opts.keepFiles,
+			columns:   opts.columns,
+			owners:    newOwners(),
+		}
+	default:
+		r = &textRenderer{
+			out:       out,
+			colors:    colorsFor(out, opts),
+			sizeUnits: opts.sizeUnits,
+			keepFiles: opts.keepFiles,
+			columns:   opts.columns,
+			owners:    newOwners(),
+			lines:     newTreeLines(opts.charset, opts.indent),
+		}
+	}
+
+	if opts.report {
+		r = newCountingRenderer(r, opts)
+	}
+
+	r.start(root, rootDir)
+
+	return r
+}
+
+func entryType(file *tree.Node) string {
+	switch {
+	case file.IsDir():
+		return "directory"
+	case file.Mode&os.ModeSymlink != 0:
+		return "link"
+	case file.Mode.IsRegular():
+		return "file"
+	default:
+		return "other"
+	}
+}
+
+type textRenderer struct {
+	out       io.Writer
+	colors    *colorScheme
+	sizeUnits int
+	keepFiles bool
+	columns   int
+	owners    *owners
+	lines     treeLines
+	prefix    []string
+}
+
+func (r *textRenderer) start(root string, dir *tree.Node) {}
+
+func (r *textRenderer) file(path string, file *tree.Node, isLast bool) {
+	printOutLine(r.out, r.label(path, file), r.prefix, r.lines, isLast)
+	printOutLinkTarget(r.out, file)
+	printOutFileSize(r.out, file, r.colors, r.sizeUnits)
+	printOutDiff(r.out, file, r.sizeUnits)
+	printOutMatches(r.out, file)
+	printOutChecksum(r.out, file)
+	fmt.Fprintf(r.out, "\n")
+}
+
+func (r *textRenderer) openDir(path string, dir *tree.Node, isLast bool) {
+	printOutLine(r.out, r.label(path, dir), r.prefix, r.lines, isLast)
+	printOutLinkTarget(r.out, dir)
+
+	if dir.Recursive {
+		fmt.Fprintf(r.out, " [recursive, not followed]")
+	}
+
+	if dir.Err != nil {
+		fmt.Fprintf(r.out, " [error opening dir]")
+	}
+
+	if dir.Usage != nil {
+		printOutDirUsage(r.out, *dir.Usage, r.colors, r.sizeUnits)
+	}
+
+	printOutDiff(r.out, dir, r.sizeUnits)
+
+	fmt.Fprintf(r.out, "\n")
+
+	if isLast {
+		r.prefix = append(r.prefix, r.lines.space)
+	} else {
+		r.prefix = append(r.prefix, r.lines.pipe)
+	}
+}
+
+// label is the colored name or path of the entry preceded by the
+// requested metadata columns.
+func (r *textRenderer) label(path string, file *tree.Node) string {
+	name := r.colors.name(file.Info())
+
+	if r.columns&columnFullPath != 0 {
+		name = r.colors.name(namedFileInfo{FileInfo: file.Info(), name: path})
+	}
+
+	if r.columns&^columnFullPath == 0 {
+		return name
+	}
+
+	meta := newFileMeta(path, file.Info(), r.columns, r.owners)
+
+	return "[" + formatColumns(file.Info(), meta, r.columns) + "] " + name
+}
+
+func (r *textRenderer) closeDir() {
+	r.prefix = r.prefix[:len(r.prefix)-1]
+}
+
+func (r *textRenderer) truncated(count int) {
+	printOutTruncated(r.out, r.prefix, r.lines, count)
+}
+
+func (r *textRenderer) report(t totals) {
+	fmt.Fprintf(r.out, "\n%s\n", formatTotals(t, r.keepFiles, r.sizeUnits))
+
+	if t.types != nil && t.files != 0 {
+		fmt.Fprintf(r.out, "%s\n", formatTypes(t))
+	}
+}
+
+func (r *textRenderer) finish() error {
+	return nil
+}
+
+// namedFileInfo shows an entry under its path instead of its name.
+type namedFileInfo struct {
+	os.FileInfo
+	name string
+}
+
+func (info namedFileInfo) Name() string {
+	return info.name
+}
+
+func printOutLine(out io.Writer, label string, prefix []string, lines treeLines, isLast bool) {
+	fmt.Fprint(out, strings.Join(prefix, ""))
+
+	if isLast {
+		fmt.Fprint(out, lines.last)
+	} else {
+		fmt.Fprint(out, lines.branch)
+	}
+
+	fmt.Fprint(out, label)
+}
+
+func printOutLinkTarget(out io.Writer, file *tree.Node) {
+	if file.Target != "" {
+		fmt.Fprintf(out, " -> %s", file.Target)
+	}
+}
+
+func printOutFileSize(out io.Writer, file *tree.Node, colors *colorScheme, units int) {
+	fileSize := file.Size
+
+	if fileSize == 0 {
+		fmt.Fprintf(out, " %s", colors.size("(empty)"))
+	} else {
+		fmt.Fprintf(out, " %s", colors.size("("+formatSize(fileSize, units)+")"))
+	}
+}
+
+func printOutDirUsage(out io.Writer, usage tree.Usage, colors *colorScheme, units int) {
+	noun := "files"
+
+	if usage.Files == 1 {
+		noun = "file"
+	}
+
+	if usage.Size == 0 {
+		fmt.Fprintf(out, " %s", colors.size(fmt.Sprintf("(empty, %d %s)", usage.Files, noun)))
+	} else {
+		fmt.Fprintf(out, " %s", colors.size(fmt.Sprintf("(%s, %d %s)", formatSize(usage.Size, units), usage.Files, noun)))
+	}
+}
+
+func printOutMatches(out io.Writer, file *tree.Node) {
+	if file.Matches != 0 {
+		fmt.Fprintf(out, " [%s]", plural(file.Matches, "matching line", "matching lines"))
+	}
+}
+
+func printOutChecksum(out io.Writer, file *tree.Node) {
+	if file.Checksum != "" {
+		fmt.Fprintf(out, " %s", file.Checksum)
+	}
+
+	if file.Err != nil {
+		fmt.Fprintf(out, " [error reading file]")
+	}
+}
+
+func printOutDiff(out io.Writer, file *tree.Node, units int) {
+	switch {
+	case file.OldSize != nil:
+		fmt.Fprintf(out, " [changed, was %s]", formatSize(*file.OldSize, units))
+	case file.Change != tree.Unchanged:
+		fmt.Fprintf(out, " [%s]", diffNames[file.Change])
+	}
+}
+
+// printOutTruncated marks a directory whose content is deeper than the
+// depth limit.
+func printOutTruncated(out io.Writer, prefix []string, lines treeLines, count int) {
+	noun := "entries"
+
+	if count == 1 {
+		noun = "entry"
+	}
+
+	fmt.Fprintf(out, "%s%s... (%d %s)\n", strings.Join(prefix, ""), lines.last, count, noun)
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"hw1_tree/tree"
+)
+
+const htmlHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%[1]s</title>
+<style>
+body { font-family: monospace; }
+ul.tree, ul.tree ul { list-style: none; padding-left: 1.5em; }
+summary { cursor: pointer; font-weight: bold; }
+.size, .meta, .note { color: #777; }
+.error, .removed { color: #c00; }
+.added { color: #080; }
+.changed { color: #b60; }
+</style>
+</head>
+<body>
+<h1>%[1]s</h1>
+<ul class="tree">
+`
+
+const htmlFooter = `</ul>
+%s</body>
+</html>
+`
+
+// htmlRenderer streams a self-contained page, directories are collapsible
+// <details> elements and files link to base followed by their path from
+// the root, or to the path from ./ when base is empty.
+type htmlRenderer struct {
+	out       io.Writer
+	root      string
+	base      string
+	sizeUnits int
+	keepFiles bool
+	columns   int
+	owners    *owners
+	footer    string
+	err       error
+}
+
+func (r *htmlRenderer) start(root string, dir *tree.Node) {
+	r.root = root
+	r.write(fmt.Sprintf(htmlHeader, html.EscapeString(root)))
+}
+
+func (r *htmlRenderer) file(path string, file *tree.Node, isLast bool) {
+	r.write(fmt.Sprintf("<li>%s<a href=\"%s\">%s</a>%s%s</li>\n",
+		r.meta(path, file), html.EscapeString(r.href(path)), r.name(path, file), r.linkTarget(file), r.size(file.Size)+r.diff(file)+r.checksum(file)))
+}
+
+func (r *htmlRenderer) openDir(path string, dir *tree.Node, isLast bool) {
+	notes := r.linkTarget(dir)
+
+	if dir.Recursive {
+		notes += ` <span class="note">[recursive, not followed]</span>`
+	}
+
+	if dir.Err != nil {
+		notes += fmt.Sprintf(` <span class="error" title="%s">[error opening dir]</span>`, html.EscapeString(dir.Err.Error()))
+	}
+
+	if dir.Usage != nil {
+		notes += r.size(dir.Usage.Size, plural(dir.Usage.Files, "file", "files"))
+	}
+
+	notes += r.diff(dir)
+
+	r.write(fmt.Sprintf("<li><details open><summary>%s%s%s</summary>\n<ul>\n", r.meta(path, dir), r.name(path, dir), notes))
+}
+
+func (r *htmlRenderer) closeDir() {
+	r.write("</ul>\n</details></li>\n")
+}
+
+func (r *htmlRenderer) truncated(count int) {
+	noun := "entries"
+
+	if count == 1 {
+		noun = "entry"
+	}
+
+	r.write(fmt.Sprintf("<li class=\"note\">... (%d %s)</li>\n", count, noun))
+}
+
+func (r *htmlRenderer) report(t totals) {
+	r.footer = "<p class=\"note\">" + html.EscapeString(formatTotals(t, r.keepFiles, r.sizeUnits))
+
+	if t.types != nil && t.files != 0 {
+		r.footer += "<br>" + html.EscapeString(formatTypes(t))
+	}
+
+	r.footer += "</p>\n"
+}
+
+func (r *htmlRenderer) finish() error {
+	r.write(fmt.Sprintf(htmlFooter, r.footer))
+
+	return r.err
+}
+
+func (r *htmlRenderer) name(path string, file *tree.Node) string {
+	if r.columns&columnFullPath != 0 {
+		return html.EscapeString(path)
+	}
+
+	return html.EscapeString(file.Name)
+}
+
+func (r *htmlRenderer) meta(path string, file *tree.Node) string {
+	if r.columns&^columnFullPath == 0 {
+		return ""
+	}
+
+	meta := newFileMeta(path, file.Info(), r.columns, r.owners)
+
+	return `<span class="meta">[` + html.EscapeString(formatColumns(file.Info(), meta, r.columns)) + "]</span> "
+}
+
+func (r *htmlRenderer) checksum(file *tree.Node) string {
+	result := ""
+
+	if file.Matches != 0 {
+		result += ` <span class="note">[` + plural(file.Matches, "matching line", "matching lines") + "]</span>"
+	}
+
+	if file.Checksum != "" {
+		result += ` <span class="meta">` + file.Checksum + "</span>"
+	}
+
+	if file.Err != nil {
+		result += fmt.Sprintf(` <span class="error" title="%s">[error reading file]</span>`, html.EscapeString(file.Err.Error()))
+	}
+
+	return result
+}
+
+func (r *htmlRenderer) diff(file *tree.Node) string {
+	switch {
+	case file.OldSize != nil:
+		return ` <span class="changed">[changed, was ` + formatSize(*file.OldSize, r.sizeUnits) + "]</span>"
+	case file.Change != tree.Unchanged:
+		return fmt.Sprintf(` <span class="%[1]s">[%[1]s]</span>`, diffNames[file.Change])
+	default:
+		return ""
+	}
+}
+
+func (r *htmlRenderer) linkTarget(file *tree.Node) string {
+	if file.Target == "" {
+		return ""
+	}
+
+	return " -&gt; " + html.EscapeString(file.Target)
+}
+
+// size is shown in parentheses with optional details after it.
+func (r *htmlRenderer) size(size int64, details ...string) string {
+	text := "empty"
+
+	if size != 0 {
+		text = formatSize(size, r.sizeUnits)
+	}
+
+	return ` <span class="size">(` + strings.Join(append([]string{text}, details...), ", ") + ")</span>"
+}
+
+// href escapes every segment of the path relative to the root. Links
+// without a base start with ./, so a first segment with a colon like
+// javascript:x is not taken for a scheme.
+func (r *htmlRenderer) href(path string) string {
+	rel, err := filepath.Rel(r.root, path)
+
+	if err != nil {
+		rel = path
+	}
+
+	segments := strings.Split(filepath.ToSlash(rel), "/")
+
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	if r.base == "" {
+		return "./" + strings.Join(segments, "/")
+	}
+
+	return strings.TrimSuffix(r.base, "/") + "/" + strings.Join(segments, "/")
+}
+
+func (r *htmlRenderer) write(s string) {
+	if r.err != nil {
+		return
+	}
+
+	_, r.err = io.WriteString(r.out, s)
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"hw1_tree/tree"
+)
+
+type jsonEntry struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Size  int64  `json:"size"`
+	Mode  string `json:"mode"`
+	Mtime string `json:"mtime"`
+	// Files is set for directories in disk usage mode
+	Files     *int   `json:"files,omitempty"`
+	Target    string `json:"target,omitempty"`
+	Recursive bool   `json:"recursive,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Matches   int    `json:"matches,omitempty"`
+	Checksum  string `json:"checksum,omitempty"`
+	// Diff marks entries of a merged tree
+	Diff    string `json:"diff,omitempty"`
+	OldSize *int64 `json:"oldSize,omitempty"`
+	// metadata columns, set only when requested
+	Inode uint64 `json:"inode,omitempty"`
+	User  string `json:"user,omitempty"`
+	Group string `json:"group,omitempty"`
+	Path  string `json:"path,omitempty"`
+}
+
+type jsonTruncated struct {
+	Type    string `json:"type"`
+	Entries int    `json:"entries"`
+}
+
+type jsonReport struct {
+	Directories int            `json:"directories"`
+	Files       int            `json:"files"`
+	Size        int64          `json:"size"`
+	Types       map[string]int `json:"types,omitempty"`
+}
+
+// jsonRenderer streams a nested document, directories hold their content
+// in "children" array. Every entry is written on its own line.
+type jsonRenderer struct {
+	out io.Writer
+	// empty tells for every open directory whether nothing was written
+	// into its children array yet
+	empty   []bool
+	columns int
+	owners  *owners
+	// totals are added to the root object as "report" when set
+	totals *totals
+	err    error
+}
+
+func newJSONEntry(name string, file *tree.Node, meta fileMeta) jsonEntry {
+	result := jsonEntry{
+		Name:      name,
+		Type:      entryType(file),
+		Size:      file.Size,
+		Mode:      file.Mode.String(),
+		Mtime:     file.ModTime.Format(time.RFC3339),
+		Target:    file.Target,
+		Recursive: file.Recursive,
+		Matches:   file.Matches,
+		Checksum:  file.Checksum,
+		Diff:      diffNames[file.Change],
+		OldSize:   file.OldSize,
+		Inode:     meta.inode,
+		User:      meta.user,
+		Group:     meta.group,
+		Path:      meta.path,
+	}
+
+	if file.Err != nil {
+		result.Error = file.Err.Error()
+	}
+
+	if file.Usage != nil {
+		result.Files = &file.Usage.Files
+	}
+
+	return result
+}
+
+func (r *jsonRenderer) start(root string, dir *tree.Node) {
+	r.writeDir(newJSONEntry(root, dir, newFileMeta(root, dir.Info(), r.columns, r.owners)))
+}
+
+func (r *jsonRenderer) file(path string, file *tree.Node, isLast bool) {
+	r.writeChild(newJSONEntry(file.Name, file, newFileMeta(path, file.Info(), r.columns, r.owners)))
+}
+
+func (r *jsonRenderer) openDir(path string, dir *tree.Node, isLast bool) {
+	r.writeDir(newJSONEntry(dir.Name, dir, newFileMeta(path, dir.Info(), r.columns, r.owners)))
+}
+
+func (r *jsonRenderer) closeDir() {
+	r.closeChildren()
+	r.write("}")
+}
+
+func (r *jsonRenderer) closeChildren() {
+	empty := r.empty[len(r.empty)-1]
+	r.empty = r.empty[:len(r.empty)-1]
+
+	if empty {
+		r.write("]")
+	} else {
+		r.write("\n" + strings.Repeat("  ", len(r.empty)) + "]")
+	}
+}
+
+func (r *jsonRenderer) truncated(count int) {
+	r.writeChild(jsonTruncated{Type: "truncated", Entries: count})
+}
+
+func (r *jsonRenderer) report(t totals) {
+	r.totals = &t
+}
+
+func (r *jsonRenderer) finish() error {
+	r.closeChildren()
+
+	if r.totals != nil {
+		data, err := json.Marshal(jsonReport{
+			Directories: r.totals.dirs,
+			Files:       r.totals.files,
+			Size:        r.totals.size,
+			Types:       r.totals.types,
+		})
+
+		if err != nil {
+			r.err = err
+		}
+
+		r.write(`,"report":` + string(data))
+	}
+
+	r.write("}\n")
+
+	return r.err
+}
+
+// writeDir leaves the directory object open, its children array is
+// finished by closeDir.
+func (r *jsonRenderer) writeDir(dir jsonEntry) {
+	data, err := json.Marshal(dir)
+
+	if err != nil {
+		r.err = err
+	} else {
+		r.writeLine(append(data[:len(data)-1], `,"children":[`...))
+	}
+
+	r.empty = append(r.empty, true)
+}
+
+func (r *jsonRenderer) writeChild(value interface{}) {
+	data, err := json.Marshal(value)
+
+	if err != nil {
+		r.err = err
+
+		return
+	}
+
+	r.writeLine(data)
+}
+
+// writeLine starts a new line in the array of the innermost directory.
+func (r *jsonRenderer) writeLine(data []byte) {
+	if len(r.empty) != 0 {
+		if r.empty[len(r.empty)-1] {
+			r.empty[len(r.empty)-1] = false
+			r.write("\n")
+		} else {
+			r.write(",\n")
+		}
+	}
+
+	r.write(strings.Repeat("  ", len(r.empty)) + string(data))
+}
+
+func (r *jsonRenderer) write(s string) {
+	if r.err != nil {
+		return
+	}
+
+	_, r.err = io.WriteString(r.out, s)
+}
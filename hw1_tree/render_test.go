@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testNode struct {
+	Name     string     `json:"name" xml:"name,attr"`
+	Type     string     `json:"type"`
+	Size     int64      `json:"size" xml:"size,attr"`
+	Entries  int        `json:"entries" xml:"entries,attr"`
+	Children []testNode `json:"children"`
+}
+
+// zlineTree is testdata/zline, sizes of directories are not compared
+var zlineTree = testNode{Name: "testdata/zline", Type: "directory", Children: []testNode{
+	{Name: "empty.txt", Type: "file"},
+	{Name: "lorem", Type: "directory", Children: []testNode{
+		{Name: "dolor.txt", Type: "file"},
+		{Name: "gopher.png", Type: "file", Size: 70372},
+		{Name: "ipsum", Type: "directory", Children: []testNode{
+			{Type: "truncated", Entries: 1},
+		}},
+	}},
+}}
+
+func clearDirSizes(node *testNode) {
+	if node.Type == "directory" {
+		node.Size = 0
+	}
+
+	for i := range node.Children {
+		clearDirSizes(&node.Children[i])
+	}
+}
+
+func TestTreeJSON(t *testing.T) {
+	for name, tree := range treeFuncs {
+		out := new(bytes.Buffer)
+		err := tree(out, "testdata/zline", options{keepFiles: true, maxDepth: 2, format: formatJSON})
+		if err != nil {
+			t.Fatalf("%s: unexpected error %s", name, err)
+		}
+
+		var result testNode
+		if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+			t.Fatalf("%s: invalid JSON %s\n%s", name, err, out)
+		}
+		clearDirSizes(&result)
+		if !reflect.DeepEqual(result, zlineTree) {
+			t.Errorf("%s: results not match\nGot:\n%+v\nExpected:\n%+v", name, result, zlineTree)
+		}
+	}
+}
+
+type testXMLNode struct {
+	XMLName  xml.Name
+	Name     string        `xml:"name,attr"`
+	Size     int64         `xml:"size,attr"`
+	Entries  int           `xml:"entries,attr"`
+	Children []testXMLNode `xml:",any"`
+}
+
+func (node testXMLNode) toTestNode() testNode {
+	result := testNode{Name: node.Name, Type: node.XMLName.Local, Size: node.Size, Entries: node.Entries}
+
+	for _, child := range node.Children {
+		result.Children = append(result.Children, child.toTestNode())
+	}
+
+	return result
+}
+
+func TestTreeXML(t *testing.T) {
+	for name, tree := range treeFuncs {
+		out := new(bytes.Buffer)
+		err := tree(out, "testdata/zline", options{keepFiles: true, maxDepth: 2, format: formatXML})
+		if err != nil {
+			t.Fatalf("%s: unexpected error %s", name, err)
+		}
+
+		var doc struct {
+			Root testXMLNode `xml:"directory"`
+		}
+		if err := xml.Unmarshal(out.Bytes(), &doc); err != nil {
+			t.Fatalf("%s: invalid XML %s\n%s", name, err, out)
+		}
+		result := doc.Root.toTestNode()
+		clearDirSizes(&result)
+		if !reflect.DeepEqual(result, zlineTree) {
+			t.Errorf("%s: results not match\nGot:\n%+v\nExpected:\n%+v", name, result, zlineTree)
+		}
+	}
+}
+
+const htmlTreeResult = `<ul class="tree">
+<li><details open><summary>a &amp; b</summary>
+<ul>
+<li><a href="files/a%20&amp;%20b/x%3Fy.txt">x?y.txt</a> <span class="size">(3b)</span></li>
+</ul>
+</details></li>
+<li><a href="files/z.txt">z.txt</a> <span class="size">(empty)</span></li>
+</ul>
+<p class="note">1 directory, 2 files, 3b</p>
+</body>
+</html>
+`
+
+func TestTreeHTML(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"a & b/x?y.txt": "abc", "z.txt": ""})
+
+	for name, tree := range treeFuncs {
+		out := new(bytes.Buffer)
+		err := tree(out, root, options{keepFiles: true, format: formatHTML, htmlBase: "files/", report: true})
+		if err != nil {
+			t.Fatalf("%s: unexpected error %s", name, err)
+		}
+
+		result := out.String()
+		if !strings.HasPrefix(result, "<!DOCTYPE html>") || !strings.HasSuffix(result, htmlTreeResult) {
+			t.Errorf("%s: results not match\nGot:\n%v\nExpected to end with:\n%v", name, result, htmlTreeResult)
+		}
+	}
+}
+
+func TestHTMLHref(t *testing.T) {
+	root := filepath.Join("files", "root")
+	cases := []struct {
+		base, path, expected string
+	}{
+		{"", filepath.Join(root, "javascript:alert(1)"), "./javascript:alert%281%29"},
+		{"", filepath.Join(root, "a b", "c:d"), "./a%20b/c:d"},
+		{"files/", filepath.Join(root, "javascript:alert(1)"), "files/javascript:alert%281%29"},
+		{"https://example.com/", filepath.Join(root, "x?y"), "https://example.com/x%3Fy"},
+	}
+
+	for _, c := range cases {
+		r := &htmlRenderer{root: root, base: c.base}
+		if result := r.href(c.path); result != c.expected {
+			t.Errorf("base %q, path %q: expected %q, got %q", c.base, c.path, c.expected, result)
+		}
+	}
+}
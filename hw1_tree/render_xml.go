@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+
+	"hw1_tree/tree"
+)
+
+// xmlRenderer streams a document with <directory> elements nested into
+// each other and wrapped into a <tree> element.
+type xmlRenderer struct {
+	out io.Writer
+	enc *xml.Encoder
+	// open holds names of elements to be closed
+	open    []xml.Name
+	columns int
+	owners  *owners
+	err     error
+}
+
+func newXMLRenderer(out io.Writer, columns int) *xmlRenderer {
+	enc := xml.NewEncoder(out)
+	enc.Indent("", "  ")
+
+	return &xmlRenderer{out: out, enc: enc, columns: columns, owners: newOwners()}
+}
+
+func xmlEntry(name string, file *tree.Node, meta fileMeta) xml.StartElement {
+	element := xml.StartElement{
+		Name: xml.Name{Local: entryType(file)},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "name"}, Value: name},
+			{Name: xml.Name{Local: "size"}, Value: strconv.FormatInt(file.Size, 10)},
+			{Name: xml.Name{Local: "mode"}, Value: file.Mode.String()},
+			{Name: xml.Name{Local: "mtime"}, Value: file.ModTime.Format(time.RFC3339)},
+		},
+	}
+
+	if file.Usage != nil {
+		element.Attr = append(element.Attr, xml.Attr{Name: xml.Name{Local: "files"}, Value: strconv.Itoa(file.Usage.Files)})
+	}
+
+	if file.Target != "" {
+		element.Attr = append(element.Attr, xml.Attr{Name: xml.Name{Local: "target"}, Value: file.Target})
+	}
+
+	if file.Recursive {
+		element.Attr = append(element.Attr, xml.Attr{Name: xml.Name{Local: "recursive"}, Value: "true"})
+	}
+
+	if file.Err != nil {
+		element.Attr = append(element.Attr, xml.Attr{Name: xml.Name{Local: "error"}, Value: file.Err.Error()})
+	}
+
+	if file.Matches != 0 {
+		element.Attr = append(element.Attr, xml.Attr{Name: xml.Name{Local: "matches"}, Value: strconv.Itoa(file.Matches)})
+	}
+
+	if file.Checksum != "" {
+		element.Attr = append(element.Attr, xml.Attr{Name: xml.Name{Local: "checksum"}, Value: file.Checksum})
+	}
+
+	if file.Change != tree.Unchanged {
+		element.Attr = append(element.Attr, xml.Attr{Name: xml.Name{Local: "diff"}, Value: diffNames[file.Change]})
+	}
+
+	if file.OldSize != nil {
+		element.Attr = append(element.Attr, xml.Attr{Name: xml.Name{Local: "oldSize"}, Value: strconv.FormatInt(*file.OldSize, 10)})
+	}
+
+	if meta.inode != 0 {
+		element.Attr = append(element.Attr, xml.Attr{Name: xml.Name{Local: "inode"}, Value: strconv.FormatUint(meta.inode, 10)})
+	}
+
+	if meta.user != "" {
+		element.Attr = append(element.Attr, xml.Attr{Name: xml.Name{Local: "user"}, Value: meta.user})
+	}
+
+	if meta.group != "" {
+		element.Attr = append(element.Attr, xml.Attr{Name: xml.Name{Local: "group"}, Value: meta.group})
+	}
+
+	if meta.path != "" {
+		element.Attr = append(element.Attr, xml.Attr{Name: xml.Name{Local: "path"}, Value: meta.path})
+	}
+
+	return element
+}
+
+func (r *xmlRenderer) start(root string, dir *tree.Node) {
+	_, r.err = io.WriteString(r.out, xml.Header)
+
+	r.openElement(xml.StartElement{Name: xml.Name{Local: "tree"}})
+	r.openElement(xmlEntry(root, dir, newFileMeta(root, dir.Info(), r.columns, r.owners)))
+}
+
+func (r *xmlRenderer) file(path string, file *tree.Node, isLast bool) {
+	r.openElement(xmlEntry(file.Name, file, newFileMeta(path, file.Info(), r.columns, r.owners)))
+	r.closeElement()
+}
+
+func (r *xmlRenderer) openDir(path string, dir *tree.Node, isLast bool) {
+	r.openElement(xmlEntry(dir.Name, dir, newFileMeta(path, dir.Info(), r.columns, r.owners)))
+}
+
+func (r *xmlRenderer) closeDir() {
+	r.closeElement()
+}
+
+func (r *xmlRenderer) truncated(count int) {
+	r.openElement(xml.StartElement{
+		Name: xml.Name{Local: "truncated"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "entries"}, Value: strconv.Itoa(count)}},
+	})
+	r.closeElement()
+}
+
+// report closes the root directory and adds the totals after it.
+func (r *xmlRenderer) report(t totals) {
+	r.closeElement()
+
+	r.openElement(xml.StartElement{
+		Name: xml.Name{Local: "report"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "directories"}, Value: strconv.Itoa(t.dirs)},
+			{Name: xml.Name{Local: "files"}, Value: strconv.Itoa(t.files)},
+			{Name: xml.Name{Local: "size"}, Value: strconv.FormatInt(t.size, 10)},
+		},
+	})
+
+	for _, c := range t.sortedTypes() {
+		r.openElement(xml.StartElement{
+			Name: xml.Name{Local: "type"},
+			Attr: []xml.Attr{
+				{Name: xml.Name{Local: "extension"}, Value: c.ext},
+				{Name: xml.Name{Local: "files"}, Value: strconv.Itoa(c.count)},
+			},
+		})
+		r.closeElement()
+	}
+
+	r.closeElement()
+}
+
+func (r *xmlRenderer) finish() error {
+	for len(r.open) != 0 {
+		r.closeElement()
+	}
+
+	r.encode(xml.CharData("\n"))
+
+	if r.err == nil {
+		r.err = r.enc.Flush()
+	}
+
+	return r.err
+}
+
+func (r *xmlRenderer) openElement(element xml.StartElement) {
+	r.open = append(r.open, element.Name)
+	r.encode(element)
+}
+
+func (r *xmlRenderer) closeElement() {
+	name := r.open[len(r.open)-1]
+	r.open = r.open[:len(r.open)-1]
+	r.encode(xml.EndElement{Name: name})
+}
+
+func (r *xmlRenderer) encode(token xml.Token) {
+	if r.err != nil {
+		return
+	}
+
+	r.err = r.enc.EncodeToken(token)
+}
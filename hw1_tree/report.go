@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"hw1_tree/tree"
+)
+
+// totals counts entries shown in the tree. Links and other non
+// directories are counted as files, content cut off by the depth limit is
+// not counted.
+type totals struct {
+	dirs  int
+	files int
+	size  int64
+	// types counts files by extension when requested
+	types map[string]int
+}
+
+func (t *totals) addFile(file *tree.Node) {
+	t.files++
+	t.size += file.Size
+
+	if t.types != nil {
+		t.types[filepath.Ext(file.Name)]++
+	}
+}
+
+// countingRenderer counts entries on their way to the wrapped renderer
+// and hands the totals over to it before finishing.
+type countingRenderer struct {
+	renderer
+	totals totals
+}
+
+func newCountingRenderer(r renderer, opts options) *countingRenderer {
+	counting := &countingRenderer{renderer: r}
+
+	if opts.reportTypes {
+		counting.totals.types = make(map[string]int)
+	}
+
+	return counting
+}
+
+// file and openDir leave out removed entries of a diff, the totals are
+// those of the new tree.
+func (r *countingRenderer) file(path string, file *tree.Node, isLast bool) {
+	if file.Change != tree.Removed {
+		r.totals.addFile(file)
+	}
+
+	r.renderer.file(path, file, isLast)
+}
+
+func (r *countingRenderer) openDir(path string, dir *tree.Node, isLast bool) {
+	if dir.Change != tree.Removed {
+		r.totals.dirs++
+	}
+
+	r.renderer.openDir(path, dir, isLast)
+}
+
+func (r *countingRenderer) finish() error {
+	r.renderer.report(r.totals)
+
+	return r.renderer.finish()
+}
+
+// typeCount is the number of files with the extension.
+type typeCount struct {
+	ext   string
+	count int
+}
+
+// sortedTypes lists extensions from the most common one.
+func (t totals) sortedTypes() []typeCount {
+	types := make([]typeCount, 0, len(t.types))
+
+	for ext, count := range t.types {
+		types = append(types, typeCount{ext, count})
+	}
+
+	sort.Slice(types, func(i, j int) bool {
+		if types[i].count != types[j].count {
+			return types[i].count > types[j].count
+		}
+
+		return types[i].ext < types[j].ext
+	})
+
+	return types
+}
+
+func plural(count int, one, many string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, one)
+	}
+
+	return fmt.Sprintf("%d %s", count, many)
+}
+
+// formatTotals makes a line like "12 directories, 48 files, 3.2MiB",
+// files are left out when they are not listed.
+func formatTotals(t totals, keepFiles bool, units int) string {
+	line := plural(t.dirs, "directory", "directories")
+
+	if keepFiles {
+		line += ", " + plural(t.files, "file", "files") + ", " + formatSize(t.size, units)
+	}
+
+	return line
+}
+
+// formatTypes makes a line like "30 .go, 10 .md, 8 without extension".
+func formatTypes(t totals) string {
+	parts := []string{}
+
+	for _, c := range t.sortedTypes() {
+		if c.ext == "" {
+			parts = append(parts, fmt.Sprintf("%d without extension", c.count))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d %s", c.count, c.ext))
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+)
+
+const reportResult = "├───a_lorem\n" +
+	"│	├───dolor.txt (empty)\n" +
+	"│	├───gopher.png (70372b)\n" +
+	"│	└───ipsum\n" +
+	"│		└───... (1 entry)\n" +
+	"├───css\n" +
+	"│	└───body.css (28b)\n" +
+	"└───empty.txt (empty)\n" +
+	"\n" +
+	"3 directories, 4 files, 70400b\n" +
+	"2 .txt, 1 .css, 1 .png\n"
+
+func TestTreeReport(t *testing.T) {
+	opts := options{keepFiles: true, color: colorNever, maxDepth: 2, report: true, reportTypes: true, exclude: []string{"html", "js", "z_lorem"}}
+
+	checkTree(t, "testdata/static", opts, reportResult)
+}
+
+func TestTreeReportDirsOnly(t *testing.T) {
+	checkTree(t, "testdata/zline", options{color: colorNever, report: true}, "└───lorem\n\t└───ipsum\n\n2 directories\n")
+}
+
+func TestTreeReportJSON(t *testing.T) {
+	for name, tree := range treeFuncs {
+		out := new(bytes.Buffer)
+		err := tree(out, "testdata/zline", options{keepFiles: true, format: formatJSON, report: true})
+		if err != nil {
+			t.Fatalf("%s: unexpected error %s", name, err)
+		}
+
+		var result struct {
+			Report jsonReport `json:"report"`
+		}
+		if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+			t.Fatalf("%s: invalid JSON %s\n%s", name, err, out)
+		}
+		expected := jsonReport{Directories: 2, Files: 4, Size: 140744}
+		if result.Report.Directories != expected.Directories || result.Report.Files != expected.Files || result.Report.Size != expected.Size {
+			t.Errorf("%s: expected report %+v, got %+v", name, expected, result.Report)
+		}
+	}
+}
+
+func TestTreeReportXML(t *testing.T) {
+	out := new(bytes.Buffer)
+	err := dirTreeIterative(out, "testdata/project", options{keepFiles: true, format: formatXML, report: true, reportTypes: true})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	var doc struct {
+		Report struct {
+			Files int `xml:"files,attr"`
+			Types []struct {
+				Extension string `xml:"extension,attr"`
+			} `xml:"type"`
+		} `xml:"report"`
+	}
+	if err := xml.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid XML %s\n%s", err, out)
+	}
+	if doc.Report.Files != 2 || len(doc.Report.Types) != 2 || doc.Report.Types[0].Extension != ".png" {
+		t.Errorf("unexpected report %+v", doc.Report)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	sizeBytes = iota
+	// sizeBinary uses powers of 1024 (KiB, MiB, ...), sizeSI powers of 1000
+	sizeBinary
+	sizeSI
+)
+
+var (
+	binaryUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	siUnits     = []string{"B", "kB", "MB", "GB", "TB", "PB", "EB"}
+)
+
+func formatSize(size int64, units int) string {
+	switch units {
+	case sizeBinary:
+		return formatScaledSize(size, 1024, binaryUnits)
+	case sizeSI:
+		return formatScaledSize(size, 1000, siUnits)
+	default:
+		return fmt.Sprintf("%db", size)
+	}
+}
+
+// parseSize reads sizes like 512, 19b, 70.4kB, 68.7KiB or 10M, single
+// letter units are powers of 1024.
+func parseSize(text string) (int64, error) {
+	number := strings.TrimRightFunc(text, func(r rune) bool {
+		return r < '0' || r > '9'
+	})
+	unit := text[len(number):]
+
+	value, err := strconv.ParseFloat(number, 64)
+
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size %q", text)
+	}
+
+	multiplier, ok := sizeMultiplier(unit)
+
+	if !ok {
+		return 0, fmt.Errorf("invalid size unit %q", unit)
+	}
+
+	return int64(math.Round(value * multiplier)), nil
+}
+
+func sizeMultiplier(unit string) (float64, bool) {
+	switch unit {
+	case "", "b", "B":
+		return 1, true
+	}
+
+	for i := 1; i < len(binaryUnits); i++ {
+		if unit == binaryUnits[i] || unit == binaryUnits[i][:1] {
+			return math.Pow(1024, float64(i)), true
+		}
+
+		if unit == siUnits[i] {
+			return math.Pow(1000, float64(i)), true
+		}
+	}
+
+	return 0, false
+}
+
+func formatScaledSize(size int64, base float64, units []string) string {
+	if size < int64(base) {
+		return fmt.Sprintf("%d%s", size, units[0])
+	}
+
+	value := float64(size)
+	unit := 0
+
+	for value >= base && unit < len(units)-1 {
+		value /= base
+		unit++
+	}
+
+	return fmt.Sprintf("%.1f%s", value, units[unit])
+}
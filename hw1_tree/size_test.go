@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestFormatSize(t *testing.T) {
+	cases := []struct {
+		size     int64
+		units    int
+		expected string
+	}{
+		{70372, sizeBytes, "70372b"},
+		{19, sizeBinary, "19B"},
+		{1024, sizeBinary, "1.0KiB"},
+		{70372, sizeBinary, "68.7KiB"},
+		{3 << 30, sizeBinary, "3.0GiB"},
+		{999, sizeSI, "999B"},
+		{70372, sizeSI, "70.4kB"},
+		{1500000, sizeSI, "1.5MB"},
+	}
+
+	for _, c := range cases {
+		if result := formatSize(c.size, c.units); result != c.expected {
+			t.Errorf("%d: expected %q, got %q", c.size, c.expected, result)
+		}
+	}
+}
+
+const humanReadableResult = "├───file.txt (19B)\n" +
+	"└───gopher.png (68.7KiB)\n"
+
+func TestTreeHumanReadable(t *testing.T) {
+	checkTree(t, "testdata/project", options{keepFiles: true, color: colorNever, sizeUnits: sizeBinary}, humanReadableResult)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"path/filepath"
+
+	"hw1_tree/tree"
+)
+
+const (
+	sortByName = iota
+	sortBySize
+	sortByTime
+	sortByExtension
+	sortByVersion
+)
+
+var sortOrders = map[string]int{
+	"name":      sortByName,
+	"size":      sortBySize,
+	"mtime":     sortByTime,
+	"extension": sortByExtension,
+	"version":   sortByVersion,
+}
+
+var sortComparators = map[int]func(a, b *tree.Node) bool{
+	sortByName:      lessByName,
+	sortBySize:      lessBySize,
+	sortByTime:      lessByTime,
+	sortByExtension: lessByExtension,
+	sortByVersion:   lessByVersion,
+}
+
+// lessFunc orders entries of a directory the way options ask for.
+func lessFunc(opts options) func(a, b *tree.Node) bool {
+	less := sortComparators[opts.sortBy]
+
+	return func(a, b *tree.Node) bool {
+		if opts.dirsFirst && a.IsDir() != b.IsDir() {
+			return a.IsDir()
+		}
+
+		if opts.reverse {
+			a, b = b, a
+		}
+
+		return less(a, b)
+	}
+}
+
+func lessByName(a, b *tree.Node) bool {
+	return a.Name < b.Name
+}
+
+// lessBySize puts the biggest entries first.
+func lessBySize(a, b *tree.Node) bool {
+	if a.Size != b.Size {
+		return a.Size > b.Size
+	}
+
+	return lessByName(a, b)
+}
+
+// lessByTime puts the most recently modified entries first.
+func lessByTime(a, b *tree.Node) bool {
+	if !a.ModTime.Equal(b.ModTime) {
+		return a.ModTime.After(b.ModTime)
+	}
+
+	return lessByName(a, b)
+}
+
+func lessByExtension(a, b *tree.Node) bool {
+	extA, extB := filepath.Ext(a.Name), filepath.Ext(b.Name)
+
+	if extA != extB {
+		return extA < extB
+	}
+
+	return lessByName(a, b)
+}
+
+func lessByVersion(a, b *tree.Node) bool {
+	if result := compareVersions(a.Name, b.Name); result != 0 {
+		return result < 0
+	}
+
+	return lessByName(a, b)
+}
+
+// compareVersions compares names in natural order: runs of digits are
+// compared as numbers, so "file2" goes before "file10".
+func compareVersions(a, b string) int {
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			numA, restA := splitDigits(a)
+			numB, restB := splitDigits(b)
+
+			if result := compareNumbers(numA, numB); result != 0 {
+				return result
+			}
+
+			a, b = restA, restB
+
+			continue
+		}
+
+		if a[0] != b[0] {
+			if a[0] < b[0] {
+				return -1
+			}
+
+			return 1
+		}
+
+		a, b = a[1:], b[1:]
+	}
+
+	return len(a) - len(b)
+}
+
+func isDigit(char byte) bool {
+	return char >= '0' && char <= '9'
+}
+
+func splitDigits(s string) (string, string) {
+	i := 0
+
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+
+	return s[:i], s[i:]
+}
+
+// compareNumbers compares decimal numbers of any length.
+func compareNumbers(a, b string) int {
+	for len(a) > 1 && a[0] == '0' {
+		a = a[1:]
+	}
+
+	for len(b) > 1 && b[0] == '0' {
+		b = b[1:]
+	}
+
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+
+	if a < b {
+		return -1
+	}
+
+	if a > b {
+		return 1
+	}
+
+	return 0
+}
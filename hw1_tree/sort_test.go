@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"hw1_tree/tree"
+)
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"file2", "file10", -1},
+		{"file10", "file2", 1},
+		{"v1.2.10", "v1.10.1", -1},
+		{"file007", "file7", 0},
+		{"file", "file1", -1},
+		{"a", "b", -1},
+	}
+
+	for _, c := range cases {
+		result := compareVersions(c.a, c.b)
+		if (result < 0) != (c.expected < 0) || (result > 0) != (c.expected > 0) {
+			t.Errorf("%q vs %q: expected %d, got %d", c.a, c.b, c.expected, result)
+		}
+	}
+}
+
+func TestLessFunc(t *testing.T) {
+	now := time.Now()
+	files := []*tree.Node{
+		{Name: "file10.txt", Size: 5, ModTime: now},
+		{Name: "file2.go", Size: 30, ModTime: now.Add(-time.Hour)},
+		{Name: "b_dir", Mode: os.ModeDir, ModTime: now.Add(-2 * time.Hour)},
+		{Name: "a.md", Size: 20, ModTime: now.Add(time.Hour)},
+	}
+
+	cases := []struct {
+		name     string
+		opts     options
+		expected []string
+	}{
+		{"name", options{}, []string{"a.md", "b_dir", "file10.txt", "file2.go"}},
+		{"size", options{sortBy: sortBySize}, []string{"file2.go", "a.md", "file10.txt", "b_dir"}},
+		{"mtime", options{sortBy: sortByTime}, []string{"a.md", "file10.txt", "file2.go", "b_dir"}},
+		{"extension", options{sortBy: sortByExtension}, []string{"b_dir", "file2.go", "a.md", "file10.txt"}},
+		{"version", options{sortBy: sortByVersion}, []string{"a.md", "b_dir", "file2.go", "file10.txt"}},
+		{"reverse", options{sortBy: sortByVersion, reverse: true}, []string{"file10.txt", "file2.go", "b_dir", "a.md"}},
+		{"dirsfirst", options{dirsFirst: true, reverse: true}, []string{"b_dir", "file2.go", "file10.txt", "a.md"}},
+	}
+
+	for _, c := range cases {
+		sorted := append([]*tree.Node{}, files...)
+		less := lessFunc(c.opts)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return less(sorted[i], sorted[j])
+		})
+
+		names := make([]string, 0, len(sorted))
+		for _, file := range sorted {
+			names = append(names, file.Name)
+		}
+		if !reflect.DeepEqual(names, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, names)
+		}
+	}
+}
+
+const dirsFirstReverseResult = "├───z_lorem\n" +
+	"│	└───ipsum\n" +
+	"├───js\n" +
+	"│	└───site.js (10b)\n" +
+	"├───html\n" +
+	"│	└───index.html (57b)\n" +
+	"├───css\n" +
+	"│	└───body.css (28b)\n" +
+	"├───a_lorem\n" +
+	"│	└───ipsum\n" +
+	"└───empty.txt (empty)\n"
+
+func TestTreeDirsFirstReverse(t *testing.T) {
+	opts := options{keepFiles: true, color: colorNever, dirsFirst: true, reverse: true, exclude: []string{"dolor.txt", "gopher.png"}}
+
+	checkTree(t, "testdata/static", opts, dirsFirstReverseResult)
+}
@@ -0,0 +1,355 @@
+package tree
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Archive is a zip or tar archive browsed as a file system.
+type Archive interface {
+	fs.FS
+	io.Closer
+}
+
+// IsArchive tells whether the path is browsed as a directory by its
+// suffix.
+func IsArchive(name string) bool {
+	for _, suffix := range []string{".zip", ".tar", ".tar.gz", ".tgz"} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OpenArchive opens a zip archive or a tar archive, optionally gzipped,
+// by the suffix of its name. Its root is ".".
+func OpenArchive(name string) (Archive, error) {
+	if strings.HasSuffix(name, ".zip") {
+		return zip.OpenReader(name)
+	}
+
+	return newTarFS(name)
+}
+
+// tarEntry is a file of a tar archive described by its header,
+// directories missing in the archive are made up.
+type tarEntry struct {
+	name     string
+	mode     fs.FileMode
+	size     int64
+	modTime  time.Time
+	linkname string
+	children []*tarEntry
+}
+
+func (e *tarEntry) Name() string               { return e.name }
+func (e *tarEntry) Size() int64                { return e.size }
+func (e *tarEntry) Mode() fs.FileMode          { return e.mode }
+func (e *tarEntry) ModTime() time.Time         { return e.modTime }
+func (e *tarEntry) IsDir() bool                { return e.mode.IsDir() }
+func (e *tarEntry) Sys() interface{}           { return nil }
+func (e *tarEntry) Type() fs.FileMode          { return e.mode.Type() }
+func (e *tarEntry) Info() (fs.FileInfo, error) { return e, nil }
+
+// tarFS browses a tar archive, optionally gzipped. Only headers are kept
+// in memory, the archive is read again up to the entry every time a file
+// is opened, so hashing or searching contents is slow on big archives.
+type tarFS struct {
+	path    string
+	entries map[string]*tarEntry
+}
+
+func newTarFS(name string) (*tarFS, error) {
+	t := &tarFS{path: name, entries: make(map[string]*tarEntry)}
+	t.entries["."] = &tarEntry{name: path.Base(name), mode: fs.ModeDir | 0755}
+
+	archive, file, err := t.open()
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	for {
+		name, header, err := nextTarEntry(archive)
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		// the first of duplicated entries wins, as it is the one opened
+		if existing, ok := t.entries[name]; ok && !existing.IsDir() {
+			continue
+		}
+
+		e := t.dir(name)
+		e.mode = header.FileInfo().Mode()
+		e.size = header.Size
+		e.modTime = header.ModTime
+		e.linkname = header.Linkname
+
+		// the size of a link is the length of its target as on disk
+		if header.Typeflag == tar.TypeSymlink {
+			e.size = int64(len(header.Linkname))
+		}
+	}
+
+	for _, e := range t.entries {
+		sort.Slice(e.children, func(i, j int) bool {
+			return e.children[i].name < e.children[j].name
+		})
+	}
+
+	return t, nil
+}
+
+// open starts reading the archive from the beginning.
+func (t *tarFS) open() (*tar.Reader, *os.File, error) {
+	file, err := os.Open(t.path)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var reader io.Reader = file
+
+	if strings.HasSuffix(t.path, "gz") {
+		gz, err := gzip.NewReader(file)
+
+		if err != nil {
+			file.Close()
+
+			return nil, nil, err
+		}
+
+		reader = gz
+	}
+
+	return tar.NewReader(reader), file, nil
+}
+
+// nextTarEntry skips entries with names which cannot be opened, like
+// absolute paths or paths going up.
+func nextTarEntry(archive *tar.Reader) (string, *tar.Header, error) {
+	for {
+		header, err := archive.Next()
+
+		if err != nil {
+			return "", nil, err
+		}
+
+		name := path.Clean(strings.TrimPrefix(header.Name, "/"))
+
+		if name != "." && fs.ValidPath(name) {
+			return name, header, nil
+		}
+	}
+}
+
+// dir returns the entry of the name creating it and its parents as
+// directories when they are missing.
+func (t *tarFS) dir(name string) *tarEntry {
+	if e, ok := t.entries[name]; ok {
+		return e
+	}
+
+	parent := t.dir(path.Dir(name))
+	e := &tarEntry{name: path.Base(name), mode: fs.ModeDir | 0755}
+	parent.children = append(parent.children, e)
+	t.entries[name] = e
+
+	return e
+}
+
+// maxLinks limits symbolic links followed to resolve a name, as loops are
+// possible in an archive as well
+const maxLinks = 40
+
+// resolve finds the entry of the name following symbolic links of every
+// directory on the way, and of the name itself when followLast is set.
+// Links going out of the archive are not found.
+func (t *tarFS) resolve(op string, name string, followLast bool) (string, *tarEntry, error) {
+	if !fs.ValidPath(name) {
+		return "", nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	resolved, rest, links := ".", splitName(name), 0
+
+	for len(rest) != 0 {
+		next := path.Join(resolved, rest[0])
+		rest = rest[1:]
+		e, ok := t.entries[next]
+
+		if !ok {
+			return "", nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+
+		if e.mode&fs.ModeSymlink == 0 || len(rest) == 0 && !followLast {
+			resolved = next
+
+			continue
+		}
+
+		if links++; links > maxLinks {
+			return "", nil, &fs.PathError{Op: op, Path: name, Err: errors.New("too many links")}
+		}
+
+		target := path.Join(path.Dir(next), e.linkname)
+
+		if path.IsAbs(e.linkname) || target == ".." || strings.HasPrefix(target, "../") {
+			return "", nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+
+		resolved, rest = ".", append(splitName(target), rest...)
+	}
+
+	return resolved, t.entries[resolved], nil
+}
+
+// splitName returns elements of a valid name, none for the root.
+func splitName(name string) []string {
+	if name == "." {
+		return nil
+	}
+
+	return strings.Split(name, "/")
+}
+
+// Close implements Archive, the archive is only open while it is read.
+func (t *tarFS) Close() error {
+	return nil
+}
+
+func (t *tarFS) Stat(name string) (fs.FileInfo, error) {
+	_, e, err := t.resolve("stat", name, true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func (t *tarFS) Open(name string) (fs.File, error) {
+	name, e, err := t.resolve("open", name, true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if e.IsDir() {
+		return &tarDir{entry: e}, nil
+	}
+
+	if !e.mode.IsRegular() {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: errors.New("not a regular file")}
+	}
+
+	archive, file, err := t.open()
+
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	for {
+		entryName, _, err := nextTarEntry(archive)
+
+		if err == io.EOF {
+			err = fs.ErrNotExist
+		}
+
+		if err != nil {
+			file.Close()
+
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+
+		if entryName == name {
+			return &tarFile{entry: e, Reader: archive, file: file}, nil
+		}
+	}
+}
+
+func (t *tarFS) ReadLink(name string) (string, error) {
+	_, e, err := t.resolve("readlink", name, false)
+
+	if err != nil {
+		return "", err
+	}
+
+	if e.mode&fs.ModeSymlink == 0 {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+	}
+
+	return e.linkname, nil
+}
+
+func (t *tarFS) Lstat(name string) (fs.FileInfo, error) {
+	_, e, err := t.resolve("lstat", name, false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// tarFile streams the content of an entry keeping the archive open.
+type tarFile struct {
+	entry *tarEntry
+	*tar.Reader
+	file *os.File
+}
+
+func (f *tarFile) Stat() (fs.FileInfo, error) { return f.entry, nil }
+func (f *tarFile) Close() error               { return f.file.Close() }
+
+type tarDir struct {
+	entry  *tarEntry
+	offset int
+}
+
+func (d *tarDir) Stat() (fs.FileInfo, error) { return d.entry, nil }
+func (d *tarDir) Close() error               { return nil }
+
+func (d *tarDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.entry.name, Err: errors.New("is a directory")}
+}
+
+func (d *tarDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entry.children[d.offset:]
+
+	if n > 0 && len(rest) == 0 {
+		return nil, io.EOF
+	}
+
+	if n <= 0 || n > len(rest) {
+		n = len(rest)
+	}
+
+	entries := make([]fs.DirEntry, n)
+
+	for i := range entries {
+		entries[i] = rest[i]
+	}
+
+	d.offset += n
+
+	return entries, nil
+}
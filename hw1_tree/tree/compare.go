@@ -0,0 +1,99 @@
+package tree
+
+import "sort"
+
+// changes of nodes merged by Merge
+const (
+	Unchanged = iota
+	Added
+	Removed
+	// Changed is a file of another size or a node of another type
+	Changed
+)
+
+// Merge matches old and new children of a directory by names and returns
+// copies of them marked with changes, nodes found on one side only are
+// marked with everything inside them. Files of the same size are changed
+// when byTime is set and their modification times differ. Content of
+// directories truncated on the old side is left unmarked, there is
+// nothing to compare it to. Merged nodes are ordered by less, by name
+// when it is nil.
+func Merge(oldNodes []*Node, newNodes []*Node, less func(a, b *Node) bool, byTime bool) []*Node {
+	oldByName := make(map[string]*Node, len(oldNodes))
+
+	for _, node := range oldNodes {
+		oldByName[node.Name] = node
+	}
+
+	merged := make([]*Node, 0, len(newNodes))
+
+	for _, node := range newNodes {
+		old, ok := oldByName[node.Name]
+
+		if !ok {
+			merged = append(merged, mark(node, Added))
+
+			continue
+		}
+
+		delete(oldByName, node.Name)
+
+		switch {
+		case old.IsDir() != node.IsDir():
+			node = mark(node, Added)
+			node.Change = Changed
+		case node.IsDir() && old.Truncated:
+			node = mark(node, Unchanged)
+		case node.IsDir():
+			dir := *node
+			dir.Children = Merge(old.Children, node.Children, less, byTime)
+			node = &dir
+		case old.Size != node.Size:
+			node = mark(node, Changed)
+			oldSize := old.Size
+			node.OldSize = &oldSize
+		case byTime && !old.ModTime.Equal(node.ModTime):
+			node = mark(node, Changed)
+		default:
+			node = mark(node, Unchanged)
+		}
+
+		merged = append(merged, node)
+	}
+
+	for _, node := range oldNodes {
+		if _, ok := oldByName[node.Name]; ok {
+			merged = append(merged, mark(node, Removed))
+		}
+	}
+
+	if less == nil {
+		less = byName
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return less(merged[i], merged[j])
+	})
+
+	return merged
+}
+
+// mark returns a copy of the node with everything inside marked.
+func mark(node *Node, change int) *Node {
+	marked := *node
+	marked.Change = change
+
+	if len(node.Children) != 0 {
+		marked.Children = make([]*Node, len(node.Children))
+
+		for i, child := range node.Children {
+			marked.Children[i] = mark(child, change)
+		}
+	}
+
+	return &marked
+}
+
+func byName(a, b *Node) bool {
+	return a.Name < b.Name
+}
@@ -0,0 +1,70 @@
+package tree
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestMerge(t *testing.T) {
+	oldFS := fstest.MapFS{
+		"a/f.txt":  {Data: []byte("old")},
+		"a/same":   {Data: []byte("same")},
+		"gone/x":   {Data: []byte("x")},
+		"kind/y":   {Data: []byte("y")},
+		"keep.txt": {Data: []byte("keep")},
+	}
+	newFS := fstest.MapFS{
+		"a/f.txt":  {Data: []byte("newer")},
+		"a/same":   {Data: []byte("same")},
+		"kind":     {Data: []byte("file")},
+		"keep.txt": {Data: []byte("keep")},
+		"new/z":    {Data: []byte("z")},
+	}
+
+	oldRoot, err := Build(oldFS, ".", Options{Files: true})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	newRoot, err := Build(newFS, ".", Options{Files: true})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	merged := Merge(oldRoot.Children, newRoot.Children, nil, false)
+	changes := make(map[string]int)
+	var collect func(nodes []*Node, prefix string)
+	collect = func(nodes []*Node, prefix string) {
+		for _, node := range nodes {
+			changes[prefix+node.Name] = node.Change
+			collect(node.Children, prefix+node.Name+"/")
+		}
+	}
+	collect(merged, "")
+
+	expected := map[string]int{
+		"a":        Unchanged,
+		"a/f.txt":  Changed,
+		"a/same":   Unchanged,
+		"gone":     Removed,
+		"gone/x":   Removed,
+		"keep.txt": Unchanged,
+		"kind":     Changed,
+		"new":      Added,
+		"new/z":    Added,
+	}
+	for name, change := range expected {
+		if changes[name] != change {
+			t.Errorf("%s: expected change %d, got %d", name, change, changes[name])
+		}
+	}
+	if len(changes) != len(expected) {
+		t.Errorf("expected %d nodes, got %v", len(expected), changes)
+	}
+
+	if size := merged[0].Children[0].OldSize; size == nil || *size != 3 {
+		t.Errorf("expected old size of a/f.txt to be kept")
+	}
+	if newRoot.Children[0].Children[0].Change != Unchanged {
+		t.Errorf("expected merged trees to be left as they are")
+	}
+}
@@ -0,0 +1,207 @@
+package tree
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// summary describes the content of a directory read ahead of time, usage
+// counts everything beneath it which passed the filters.
+type summary struct {
+	Usage
+	// matched tells whether there is a selected file
+	matched bool
+}
+
+func (s *summary) add(other summary) {
+	s.matched = s.matched || other.matched
+	s.Size += other.Size
+	s.Files += other.Files
+}
+
+func nodeTypes(node *Node) int {
+	mode := node.Mode
+
+	switch {
+	case mode.IsDir():
+		return TypeDir
+	case mode&fs.ModeSymlink != 0:
+		return TypeSymlink
+	case mode&fs.ModeSocket != 0:
+		return TypeSocket
+	case mode&fs.ModeDevice != 0:
+		return TypeDevice
+	case mode.IsRegular() && mode&0111 != 0:
+		return TypeRegular | TypeExecutable
+	case mode.IsRegular():
+		return TypeRegular
+	default:
+		return 0
+	}
+}
+
+// filtersFiles tells whether files are selected by size, time or type.
+func filtersFiles(opts Options) bool {
+	return opts.MinSize != 0 || opts.MaxSize != 0 || !opts.Newer.IsZero() || !opts.Older.IsZero() || opts.Types != 0
+}
+
+// passesFilters checks size, modification time and type of a file.
+func passesFilters(file *Node, opts Options) bool {
+	if opts.Types != 0 && nodeTypes(file)&opts.Types == 0 {
+		return false
+	}
+
+	if file.Size < opts.MinSize || opts.MaxSize != 0 && file.Size > opts.MaxSize {
+		return false
+	}
+
+	if !opts.Newer.IsZero() && file.ModTime.Before(opts.Newer) {
+		return false
+	}
+
+	return opts.Older.IsZero() || file.ModTime.Before(opts.Older)
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		// patterns are validated by NewWalker
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// skipEntry tells whether the entry is hidden, excluded by patterns or
+// ignore files no matter what is inside.
+func (w *Walker) skipEntry(dir string, info fs.FileInfo) bool {
+	if !w.opts.Hidden && strings.HasPrefix(info.Name(), ".") {
+		return true
+	}
+
+	if matchesAny(w.opts.Exclude, info.Name()) {
+		return true
+	}
+
+	return w.ignore != nil && w.ignore.ignored(w.fsys, dir, info)
+}
+
+// prunesDirs tells whether directories without selected files are
+// dropped, directories selected by type are kept even if empty.
+func prunesDirs(opts Options) bool {
+	return (len(opts.Include) != 0 || opts.Grep != nil || filtersFiles(opts)) && opts.Types&TypeDir == 0
+}
+
+// readsAhead tells whether whole subtrees are read before they are shown,
+// regardless of the depth limit.
+func readsAhead(opts Options) bool {
+	return prunesDirs(opts) || opts.DiskUsage
+}
+
+// filterEntries drops excluded and git ignored entries and files not
+// matching include patterns, size, time and type filters or without lines
+// matching the content pattern. When any of them is set, directories are
+// read ahead and pruned if nothing beneath them matches, unless
+// directories are selected by type. Disk usage mode reads directories
+// ahead as well to sum up their content.
+func (w *Walker) filterEntries(dir string, infos []fs.FileInfo) ([]*Node, summary) {
+	var sum summary
+
+	opts := w.opts
+	files := make([]*Node, 0, len(infos))
+	prune := prunesDirs(opts)
+	readAhead := readsAhead(opts)
+
+	for _, info := range infos {
+		name := info.Name()
+
+		if w.skipEntry(dir, info) {
+			continue
+		}
+
+		file := w.newNode(join(w.fsys, dir, name), info)
+
+		if info.Mode()&fs.ModeSymlink != 0 && (opts.Links || opts.FollowLinks) {
+			w.readLink(file)
+		}
+
+		if !file.IsDir() {
+			if len(opts.Include) != 0 && !matchesAny(opts.Include, name) {
+				continue
+			}
+
+			if !passesFilters(file, opts) {
+				continue
+			}
+
+			if opts.Grep != nil {
+				if !file.Mode.IsRegular() {
+					continue
+				}
+
+				matches, err := countMatches(w.fsys, file.Path, opts.Grep)
+
+				if err != nil {
+					w.addErr(err)
+				}
+
+				if matches == 0 {
+					continue
+				}
+
+				file.Matches = matches
+			}
+
+			sum.matched = true
+			sum.Size += file.Size
+			sum.Files++
+			files = append(files, file)
+
+			continue
+		}
+
+		// links to directories which were already listed are not followed
+		if file.Recursive {
+			if !prune {
+				files = append(files, file)
+			}
+
+			continue
+		}
+
+		if !readAhead {
+			files = append(files, file)
+
+			continue
+		}
+
+		children, dirSummary, err := w.readDir(file.Path)
+		file.Loaded = true
+
+		// unreadable directories are kept to be shown with the error
+		if err != nil {
+			w.addErr(err)
+			file.Err = err
+			files = append(files, file)
+
+			continue
+		}
+
+		if prune && !dirSummary.matched {
+			continue
+		}
+
+		sum.add(dirSummary)
+		file.Children = children
+
+		if opts.DiskUsage {
+			file.setUsage(dirSummary.Usage)
+		}
+
+		files = append(files, file)
+	}
+
+	return files, sum
+}
@@ -0,0 +1,78 @@
+package tree
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Host is the file system of the operating system. Unlike os.DirFS it
+// takes OS paths, absolute or relative to the working directory, so paths
+// of nodes stay as given and parent directories can be reached.
+var Host fs.FS = hostFS{}
+
+type hostFS struct{}
+
+func (hostFS) Open(name string) (fs.File, error)      { return os.Open(name) }
+func (hostFS) Stat(name string) (fs.FileInfo, error)  { return os.Stat(name) }
+func (hostFS) Lstat(name string) (fs.FileInfo, error) { return os.Lstat(name) }
+func (hostFS) ReadLink(name string) (string, error)   { return os.Readlink(name) }
+
+func isHost(fsys fs.FS) bool {
+	_, ok := fsys.(hostFS)
+
+	return ok
+}
+
+// join appends a name to a path of the file system.
+func join(fsys fs.FS, dir string, name string) string {
+	if isHost(fsys) {
+		return filepath.Join(dir, name)
+	}
+
+	return path.Join(dir, name)
+}
+
+// openDir opens a directory for reading in chunks.
+func openDir(fsys fs.FS, name string) (fs.ReadDirFile, error) {
+	file, err := fsys.Open(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dir, ok := file.(fs.ReadDirFile)
+
+	if !ok {
+		file.Close()
+
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+
+	return dir, nil
+}
+
+// readInfos reads up to n entries of the directory, all of them when n is
+// not positive. Entries gone since they were listed are skipped.
+func readInfos(dir fs.ReadDirFile, n int) ([]fs.FileInfo, error) {
+	entries, err := dir.ReadDir(n)
+	infos := make([]fs.FileInfo, 0, len(entries))
+
+	for _, entry := range entries {
+		info, infoErr := entry.Info()
+
+		if errors.Is(infoErr, fs.ErrNotExist) {
+			continue
+		}
+
+		if infoErr != nil {
+			return infos, infoErr
+		}
+
+		infos = append(infos, info)
+	}
+
+	return infos, err
+}
@@ -0,0 +1,237 @@
+package tree
+
+import (
+	"bufio"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+type ignoreRule struct {
+	pattern string
+	negate  bool
+	dirOnly bool
+	// anchored patterns contain a slash and match the path relative to
+	// the .gitignore location, others match the entry name at any level
+	anchored bool
+}
+
+type ignoreFile struct {
+	dir   string
+	rules []ignoreRule
+}
+
+// gitIgnore matches entries against .gitignore files of their directory
+// and of every parent directory up to the repository root, or up to the
+// root of a file system other than Host. Parsed files are cached per
+// directory, so each one is read only once per walk.
+type gitIgnore struct {
+	chains map[string][]*ignoreFile
+}
+
+func newGitIgnore() *gitIgnore {
+	return &gitIgnore{chains: make(map[string][]*ignoreFile)}
+}
+
+func (g *gitIgnore) ignored(fsys fs.FS, dir string, file fs.FileInfo) bool {
+	if file.Name() == ".git" {
+		return true
+	}
+
+	// paths of other file systems are kept as they are, the root of the
+	// file system is as far as parents go
+	if isHost(fsys) {
+		absDir, err := filepath.Abs(dir)
+
+		if err != nil {
+			return false
+		}
+
+		dir = absDir
+	}
+
+	fullPath := join(fsys, dir, file.Name())
+	ignored := false
+
+	// the last matching rule wins, deeper files take precedence
+	for _, ignoreFile := range g.chain(fsys, dir) {
+		rel, ok := relPath(fsys, ignoreFile.dir, fullPath)
+
+		if !ok {
+			continue
+		}
+
+		for _, rule := range ignoreFile.rules {
+			if rule.matches(rel, file.IsDir()) {
+				ignored = !rule.negate
+			}
+		}
+	}
+
+	return ignored
+}
+
+func (g *gitIgnore) chain(fsys fs.FS, dir string) []*ignoreFile {
+	if chain, ok := g.chains[dir]; ok {
+		return chain
+	}
+
+	var chain []*ignoreFile
+
+	parent := parentDir(fsys, dir)
+
+	if parent != dir && !isRepoRoot(fsys, dir) {
+		chain = g.chain(fsys, parent)
+	}
+
+	if ignoreFile := readIgnoreFile(fsys, dir); ignoreFile != nil {
+		chain = append(chain[:len(chain):len(chain)], ignoreFile)
+	}
+
+	g.chains[dir] = chain
+
+	return chain
+}
+
+// parentDir returns the parent of the directory, the root of a file
+// system other than Host is its own parent.
+func parentDir(fsys fs.FS, dir string) string {
+	if isHost(fsys) {
+		return filepath.Dir(dir)
+	}
+
+	if dir == "." {
+		return dir
+	}
+
+	return path.Dir(dir)
+}
+
+// relPath returns the slash separated path of name relative to dir.
+func relPath(fsys fs.FS, dir string, name string) (string, bool) {
+	if isHost(fsys) {
+		rel, err := filepath.Rel(dir, name)
+
+		return filepath.ToSlash(rel), err == nil
+	}
+
+	if dir == "." {
+		return name, true
+	}
+
+	rel, ok := strings.CutPrefix(name, dir+"/")
+
+	return rel, ok
+}
+
+func isRepoRoot(fsys fs.FS, dir string) bool {
+	_, err := fs.Stat(fsys, join(fsys, dir, ".git"))
+
+	return err == nil
+}
+
+func readIgnoreFile(fsys fs.FS, dir string) *ignoreFile {
+	file, err := fsys.Open(join(fsys, dir, ".gitignore"))
+
+	if err != nil {
+		return nil
+	}
+
+	defer file.Close()
+
+	ignoreFile := &ignoreFile{dir: dir}
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreRule(scanner.Text()); ok {
+			ignoreFile.rules = append(ignoreFile.rules, rule)
+		}
+	}
+
+	return ignoreFile
+}
+
+func parseIgnoreRule(line string) (ignoreRule, bool) {
+	var rule ignoreRule
+
+	line = strings.TrimSuffix(line, "\r")
+
+	if strings.HasSuffix(line, "\\ ") {
+		line = strings.TrimRight(line[:len(line)-2], " ") + "\\ "
+	} else {
+		line = strings.TrimRight(line, " ")
+	}
+
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule, false
+	}
+
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, "\\!") || strings.HasPrefix(line, "\\#") {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+
+	if strings.Contains(line, "/") {
+		rule.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+
+	if line == "" {
+		return rule, false
+	}
+
+	rule.pattern = line
+
+	return rule, true
+}
+
+func (rule ignoreRule) matches(rel string, isDir bool) bool {
+	if rule.dirOnly && !isDir {
+		return false
+	}
+
+	if !rule.anchored {
+		ok, _ := path.Match(rule.pattern, path.Base(rel))
+
+		return ok
+	}
+
+	return matchSegments(strings.Split(rule.pattern, "/"), strings.Split(rel, "/"))
+}
+
+// matchSegments matches slash separated path segments, "**" stands for
+// any number of segments including none.
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) != 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+
+		pattern = pattern[1:]
+		name = name[1:]
+	}
+
+	return len(name) == 0
+}
@@ -0,0 +1,83 @@
+package tree
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func writeFiles(t testing.TB, root string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestIgnoreRuleMatches(t *testing.T) {
+	cases := []struct {
+		line    string
+		rel     string
+		isDir   bool
+		matches bool
+	}{
+		{"*.log", "app.log", false, true},
+		{"*.log", "src/deep/app.log", false, true},
+		{"build/", "build", true, true},
+		{"build/", "build", false, false},
+		{"/vendor", "vendor", true, true},
+		{"/vendor", "src/vendor", true, false},
+		{"doc/*.txt", "doc/notes.txt", false, true},
+		{"doc/*.txt", "doc/sub/notes.txt", false, false},
+		{"**/cache", "a/b/cache", true, true},
+		{"a/**/b", "a/b", true, true},
+		{"a/**/b", "a/x/y/b", true, true},
+		{"\\#file", "#file", false, true},
+	}
+
+	for _, c := range cases {
+		rule, ok := parseIgnoreRule(c.line)
+		if !ok {
+			t.Errorf("%q: expected rule to be parsed", c.line)
+			continue
+		}
+		if rule.matches(c.rel, c.isDir) != c.matches {
+			t.Errorf("%q against %q: expected %v", c.line, c.rel, c.matches)
+		}
+	}
+
+	for _, line := range []string{"", "   ", "# comment", "/"} {
+		if _, ok := parseIgnoreRule(line); ok {
+			t.Errorf("%q: expected line to be skipped", line)
+		}
+	}
+}
+
+func TestBuildGitIgnore(t *testing.T) {
+	fsys := fstest.MapFS{
+		".gitignore":     {Data: []byte("*.log\n/build/\n")},
+		"app.log":        {Data: []byte("log")},
+		"build/out":      {Data: []byte("out")},
+		"src/.gitignore": {Data: []byte("!keep.log\n")},
+		"src/keep.log":   {Data: []byte("log")},
+		"src/main.go":    {Data: []byte("package main\n")},
+		"src/build/x":    {Data: []byte("x")},
+	}
+
+	root, err := Build(fsys, "src", Options{Files: true, GitIgnore: true})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	expected := "├───build\n│	└───x (1b)\n├───keep.log (3b)\n└───main.go (13b)\n"
+	if result := render(t, root); result != expected {
+		t.Errorf("results not match\nGot:\n%v\nExpected:\n%v", result, expected)
+	}
+}
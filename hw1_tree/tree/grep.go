@@ -0,0 +1,41 @@
+package tree
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/fs"
+	"regexp"
+)
+
+// countMatches returns the number of lines of the file matching the
+// pattern, lines are read one by one so big files are not loaded whole.
+func countMatches(fsys fs.FS, path string, pattern *regexp.Regexp) (int, error) {
+	file, err := fsys.Open(path)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	count := 0
+
+	for {
+		line, err := reader.ReadBytes('\n')
+
+		// line ends are not part of the line, so $ matches before them
+		if len(line) != 0 && pattern.Match(bytes.TrimSuffix(bytes.TrimSuffix(line, []byte("\n")), []byte("\r"))) {
+			count++
+		}
+
+		if err == io.EOF {
+			return count, nil
+		}
+
+		if err != nil {
+			return count, err
+		}
+	}
+}
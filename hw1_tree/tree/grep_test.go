@@ -0,0 +1,34 @@
+package tree
+
+import (
+	"regexp"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCountMatches(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.txt": {Data: []byte("foo\nbar\nfood\nno newline foo")},
+		"b.txt": {Data: []byte("foo\nbar foo\r\nbaz\nfoo")},
+	}
+
+	count, err := countMatches(fsys, "a.txt", regexp.MustCompile("foo"))
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if count != 3 {
+		t.Errorf("expected 3 matching lines, got %d", count)
+	}
+
+	count, err = countMatches(fsys, "b.txt", regexp.MustCompile("foo$"))
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if count != 3 {
+		t.Errorf("expected 3 lines ending with foo, got %d", count)
+	}
+
+	if _, err := countMatches(fsys, "missing", regexp.MustCompile("foo")); err == nil {
+		t.Errorf("expected error for missing file")
+	}
+}
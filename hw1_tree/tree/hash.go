@@ -0,0 +1,136 @@
+package tree
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"hash/crc32"
+	"io"
+	"io/fs"
+)
+
+// checksum algorithms for Options.Hash
+const (
+	HashNone = iota
+	HashSHA256
+	HashMD5
+	HashCRC32
+)
+
+var hashNames = map[int]string{
+	HashSHA256: "sha256",
+	HashMD5:    "md5",
+	HashCRC32:  "crc32",
+}
+
+func newHash(algorithm int) hash.Hash {
+	switch algorithm {
+	case HashMD5:
+		return md5.New()
+	case HashCRC32:
+		return crc32.NewIEEE()
+	default:
+		return sha256.New()
+	}
+}
+
+// hashFile streams the content of the file and returns its checksum
+// prefixed with the algorithm name, e.g. "crc32:0d4a1185".
+func hashFile(fsys fs.FS, path string, algorithm int) (string, error) {
+	file, err := fsys.Open(path)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer file.Close()
+
+	h := newHash(algorithm)
+
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+
+	return hashNames[algorithm] + ":" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// checksum is the result of hashing a file.
+type checksum struct {
+	sum string
+	err error
+}
+
+// hasher computes checksums of files ahead of the walk, or as they are
+// taken when there are no workers.
+type hasher struct {
+	pool *workerPool[checksum]
+	hash func(path string) checksum
+}
+
+func newHasher(w *Walker) *hasher {
+	h := &hasher{hash: func(path string) checksum {
+		sum, err := hashFile(w.fsys, path, w.opts.Hash)
+
+		return checksum{sum: sum, err: err}
+	}}
+
+	if w.workers != nil {
+		h.pool = newWorkerPool(w.workers, h.hash)
+	}
+
+	return h
+}
+
+// schedule queues regular files of the directory which is going to be
+// taken.
+func (h *hasher) schedule(files []*Node) {
+	if h == nil || h.pool == nil {
+		return
+	}
+
+	var paths []string
+
+	for _, file := range files {
+		if hashed(file) {
+			paths = append(paths, file.Path)
+		}
+	}
+
+	h.pool.push(paths)
+}
+
+// hashed tells whether the file gets a checksum, removed files of a
+// merged tree are not there to be read.
+func hashed(file *Node) bool {
+	return file.Mode.IsRegular() && file.Change != Removed && file.Checksum == ""
+}
+
+// fill sets the checksum of the file, waiting for a worker if it is busy
+// with it. Files the workers have not got to are hashed right away, a
+// file which cannot be read gets the error, which is collected for Err as
+// well.
+func (w *Walker) fill(file *Node) {
+	h := w.hasher
+
+	if h == nil || !hashed(file) {
+		return
+	}
+
+	var c checksum
+	ok := false
+
+	if h.pool != nil {
+		c, ok = h.pool.take(file.Path)
+	}
+
+	if !ok {
+		c = h.hash(file.Path)
+	}
+
+	file.Checksum, file.Err = c.sum, c.err
+
+	if c.err != nil {
+		w.addErr(c.err)
+	}
+}
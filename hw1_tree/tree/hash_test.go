@@ -0,0 +1,55 @@
+package tree
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestHashFile(t *testing.T) {
+	cases := []struct {
+		algorithm int
+		expected  string
+	}{
+		{HashSHA256, "sha256:b03affb7e079fa1958f8ae6ea3720b46ca63fcfe1ee294618a02af7be9eed2eb"},
+		{HashMD5, "md5:122a10d6a32262217e0e79e504f2e447"},
+		{HashCRC32, "crc32:ee82b7a9"},
+	}
+
+	for _, c := range cases {
+		result, err := hashFile(Host, "../testdata/project/file.txt", c.algorithm)
+		if err != nil {
+			t.Fatalf("unexpected error %s", err)
+		}
+		if result != c.expected {
+			t.Errorf("expected %q, got %q", c.expected, result)
+		}
+	}
+
+	if _, err := hashFile(Host, "../testdata/missing", HashCRC32); err == nil {
+		t.Errorf("expected error for missing file")
+	}
+}
+
+func TestBuildHashOverPoolLimit(t *testing.T) {
+	root := t.TempDir()
+	files := make(map[string]string)
+	for i := 0; i < 3*pendingPerWorker; i++ {
+		files[fmt.Sprintf("f%03d.txt", i)] = ""
+	}
+	writeFiles(t, root, files)
+
+	node, err := Build(Host, root, Options{Files: true, Hash: HashCRC32, Jobs: 2})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	count := 0
+	for _, file := range node.Children {
+		if file.Checksum == "crc32:00000000" {
+			count++
+		}
+	}
+	if count != len(files) {
+		t.Errorf("expected %d checksums, got %d", len(files), count)
+	}
+}
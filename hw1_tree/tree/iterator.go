@@ -0,0 +1,147 @@
+package tree
+
+import (
+	"io"
+	"io/fs"
+)
+
+// streamChunk is the number of entries read at once in streaming mode
+const streamChunk = 1024
+
+// Iterator yields the content of a directory one node at a time. Files
+// come with their checksums when hashing.
+type Iterator interface {
+	// Next returns the next node and whether it is the last one, ok is
+	// false when there is nothing left
+	Next() (node *Node, isLast bool, ok bool)
+	// Count returns the number of nodes left without yielding them
+	Count() int
+	Close()
+}
+
+// sliceIterator yields children already read and sorted. Checksums of its
+// files are scheduled once the first node is taken.
+type sliceIterator struct {
+	w         *Walker
+	files     []*Node
+	scheduled bool
+}
+
+func (w *Walker) newSliceIterator(dir *Node) *sliceIterator {
+	return &sliceIterator{w: w, files: dir.Children}
+}
+
+func (it *sliceIterator) Next() (*Node, bool, bool) {
+	if !it.scheduled {
+		it.w.hasher.schedule(it.files)
+		it.scheduled = true
+	}
+
+	if len(it.files) == 0 {
+		return nil, false, false
+	}
+
+	file := it.files[0]
+	it.files = it.files[1:]
+	it.w.fill(file)
+
+	return file, len(it.files) == 0, true
+}
+
+func (it *sliceIterator) Count() int {
+	return len(it.files)
+}
+
+func (it *sliceIterator) Close() {}
+
+// dirStream reads a directory in chunks and yields filtered nodes in the
+// order of the file system, so only a chunk is held in memory.
+// Directories read ahead to prune or sum them up are still read whole.
+type dirStream struct {
+	w    *Walker
+	path string
+	dir  fs.ReadDirFile
+	// buf holds filtered nodes read so far, one node is read ahead to
+	// tell whether the previous one is the last
+	buf []*Node
+	eof bool
+}
+
+func (w *Walker) openStream(dir *Node) (*dirStream, error) {
+	file, err := openDir(w.fsys, dir.Path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+
+	if err != nil {
+		file.Close()
+
+		return nil, err
+	}
+
+	w.visit(dir.Path, info)
+
+	return &dirStream{w: w, path: dir.Path, dir: file}, nil
+}
+
+func (s *dirStream) Next() (*Node, bool, bool) {
+	for len(s.buf) < 2 && !s.eof {
+		s.read(true)
+	}
+
+	if len(s.buf) == 0 {
+		return nil, false, false
+	}
+
+	file := s.buf[0]
+	s.buf = s.buf[1:]
+	s.w.fill(file)
+
+	return file, len(s.buf) == 0, true
+}
+
+func (s *dirStream) Count() int {
+	count := len(s.buf)
+	s.buf = nil
+
+	for !s.eof {
+		s.read(false)
+		count += len(s.buf)
+		s.buf = nil
+	}
+
+	return count
+}
+
+// read appends the next chunk to the buffer. Errors in the middle of the
+// directory end it and are collected for Err.
+func (s *dirStream) read(schedule bool) {
+	infos, err := readInfos(s.dir, streamChunk)
+
+	if err != nil {
+		s.eof = true
+
+		if err != io.EOF {
+			s.w.addErr(err)
+		}
+	}
+
+	files, _ := s.w.filterEntries(s.path, infos)
+
+	if !s.w.opts.Files {
+		files = filterOutFiles(files)
+	}
+
+	if schedule {
+		s.w.hasher.schedule(files)
+	}
+
+	s.buf = append(s.buf, files...)
+}
+
+func (s *dirStream) Close() {
+	s.dir.Close()
+}
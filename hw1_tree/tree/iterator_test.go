@@ -0,0 +1,42 @@
+package tree
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirStreamCount(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"a": "", "b": "", "c/.keep": "", ".skip": ""})
+
+	w, err := NewWalker(Host, root, Options{Files: true, Hidden: true, Exclude: []string{".skip"}, Stream: true})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	defer w.Close()
+
+	_, s, err := w.Root()
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	defer s.Close()
+
+	if _, _, ok := s.Next(); !ok {
+		t.Fatalf("expected an entry")
+	}
+	if count := s.Count(); count != 2 {
+		t.Errorf("expected 2 entries left, got %d", count)
+	}
+	if _, _, ok := s.Next(); ok {
+		t.Errorf("expected no entries after count")
+	}
+
+	missing := &Node{Name: "missing", Path: filepath.Join(root, "missing"), Mode: os.ModeDir}
+	if _, err := w.Open(missing); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+	if missing.Err == nil || w.Err() == nil {
+		t.Errorf("expected the error to be kept")
+	}
+}
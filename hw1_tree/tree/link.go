@@ -0,0 +1,28 @@
+package tree
+
+import "io/fs"
+
+// readLink sets the target of a symbolic link. A followed link to a
+// directory takes metadata of the directory under its own name, links to
+// directories already listed are marked recursive.
+func (w *Walker) readLink(link *Node) {
+	link.Target, _ = fs.ReadLink(w.fsys, link.Path)
+
+	if !w.opts.FollowLinks {
+		return
+	}
+
+	target, err := fs.Stat(w.fsys, link.Path)
+
+	if err != nil || !target.IsDir() {
+		return
+	}
+
+	followed := w.newNode(link.Path, target)
+	link.Mode, link.Size, link.ModTime, link.Sys = followed.Mode, followed.Size, followed.ModTime, followed.Sys
+
+	if key, ok := newFileKey(w.fsys, link.Path, target); ok && w.visited[key] {
+		link.Recursive = true
+		link.Loaded = true
+	}
+}
@@ -0,0 +1,34 @@
+//go:build !unix
+
+package tree
+
+import (
+	"io/fs"
+	"path/filepath"
+)
+
+// fileKey identifies a directory by its absolute path with all links
+// resolved where stat data has no inode numbers.
+type fileKey struct {
+	path string
+}
+
+func newFileKey(fsys fs.FS, path string, info fs.FileInfo) (fileKey, bool) {
+	if !isHost(fsys) {
+		return fileKey{}, false
+	}
+
+	resolved, err := filepath.EvalSymlinks(path)
+
+	if err != nil {
+		return fileKey{}, false
+	}
+
+	resolved, err = filepath.Abs(resolved)
+
+	if err != nil {
+		return fileKey{}, false
+	}
+
+	return fileKey{path: resolved}, true
+}
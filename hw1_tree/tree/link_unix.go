@@ -0,0 +1,24 @@
+//go:build unix
+
+package tree
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// fileKey identifies a directory by its device and inode numbers.
+type fileKey struct {
+	dev uint64
+	ino uint64
+}
+
+func newFileKey(fsys fs.FS, path string, info fs.FileInfo) (fileKey, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+
+	if !ok {
+		return fileKey{}, false
+	}
+
+	return fileKey{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}, true
+}
@@ -0,0 +1,173 @@
+package tree
+
+import "sync"
+
+// workers run tasks queued by every pool of a traversal, so reading
+// directories ahead and hashing files share the same number of
+// goroutines.
+type workers struct {
+	count  int
+	mu     sync.Mutex
+	wakeup *sync.Cond
+	// queue is a stack, so tasks pushed last, which the depth first
+	// traversal needs next, go first
+	queue   []func()
+	stopped bool
+}
+
+func newWorkers(count int) *workers {
+	w := &workers{count: count}
+	w.wakeup = sync.NewCond(&w.mu)
+
+	for i := 0; i < count; i++ {
+		go w.work()
+	}
+
+	return w
+}
+
+func (w *workers) work() {
+	for {
+		w.mu.Lock()
+
+		for len(w.queue) == 0 && !w.stopped {
+			w.wakeup.Wait()
+		}
+
+		if w.stopped {
+			w.mu.Unlock()
+
+			return
+		}
+
+		task := w.queue[len(w.queue)-1]
+		w.queue = w.queue[:len(w.queue)-1]
+		w.mu.Unlock()
+
+		task()
+	}
+}
+
+// push queues tasks, the first one is run first.
+func (w *workers) push(tasks []func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for i := len(tasks) - 1; i >= 0; i-- {
+		w.queue = append(w.queue, tasks[i])
+	}
+
+	w.wakeup.Broadcast()
+}
+
+func (w *workers) stop() {
+	w.mu.Lock()
+	w.stopped = true
+	w.mu.Unlock()
+
+	w.wakeup.Broadcast()
+}
+
+// job is a path handled by a worker or waiting in the queue.
+type job[T any] struct {
+	path    string
+	started bool
+	done    chan struct{}
+	result  T
+}
+
+// workerPool hands paths to the workers while the traversal is busy with
+// output, so large trees do not wait for every read in turn. The
+// traversal takes results in its own order, a path nobody has started yet
+// is handled by the traversal itself.
+type workerPool[T any] struct {
+	workers *workers
+	handle  func(path string) T
+	mu      sync.Mutex
+	pending map[string]*job[T]
+	// limit caps results held for the traversal, paths pushed over it
+	// are left to the traversal
+	limit int
+}
+
+// pendingPerWorker is how many paths a worker can be ahead of the
+// traversal
+const pendingPerWorker = 16
+
+func newWorkerPool[T any](w *workers, handle func(path string) T) *workerPool[T] {
+	return &workerPool[T]{workers: w, handle: handle, pending: make(map[string]*job[T]), limit: w.count * pendingPerWorker}
+}
+
+// run handles the path of the job unless the traversal took it over.
+func (p *workerPool[T]) run(j *job[T]) {
+	p.mu.Lock()
+
+	if j.started {
+		p.mu.Unlock()
+
+		return
+	}
+
+	j.started = true
+	p.mu.Unlock()
+
+	j.result = p.handle(j.path)
+	close(j.done)
+}
+
+// push queues paths the traversal is going to take in the given order,
+// paths already pending are skipped. Only the first paths which fit under
+// the limit are queued, they are needed first.
+func (p *workerPool[T]) push(paths []string) {
+	p.mu.Lock()
+
+	var fresh []string
+
+	for _, path := range paths {
+		if _, ok := p.pending[path]; !ok {
+			fresh = append(fresh, path)
+		}
+	}
+
+	if room := p.limit - len(p.pending); len(fresh) > room {
+		fresh = fresh[:max(room, 0)]
+	}
+
+	tasks := make([]func(), len(fresh))
+
+	for i, path := range fresh {
+		j := &job[T]{path: path, done: make(chan struct{})}
+		p.pending[path] = j
+		tasks[i] = func() { p.run(j) }
+	}
+
+	p.mu.Unlock()
+
+	p.workers.push(tasks)
+}
+
+// take returns the result of a pushed path waiting for it if a worker is
+// still busy with it, ok is false for paths which were never pushed.
+func (p *workerPool[T]) take(path string) (result T, ok bool) {
+	p.mu.Lock()
+	j, ok := p.pending[path]
+	delete(p.pending, path)
+
+	if !ok {
+		p.mu.Unlock()
+
+		return result, false
+	}
+
+	if !j.started {
+		j.started = true
+		p.mu.Unlock()
+
+		return p.handle(path), true
+	}
+
+	p.mu.Unlock()
+	<-j.done
+
+	return j.result, true
+}
@@ -0,0 +1,97 @@
+package tree
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerPool(t *testing.T) {
+	var handled int32
+	w := newWorkers(3)
+	defer w.stop()
+
+	p := newWorkerPool(w, func(path string) string {
+		atomic.AddInt32(&handled, 1)
+
+		return strings.ToUpper(path)
+	})
+
+	paths := []string{"a", "b", "c", "d", "e"}
+	p.push(paths)
+	p.push(paths[:2])
+
+	for _, path := range paths {
+		result, ok := p.take(path)
+		if !ok || result != strings.ToUpper(path) {
+			t.Errorf("%s: expected %q, got %q, %v", path, strings.ToUpper(path), result, ok)
+		}
+	}
+
+	if _, ok := p.take("a"); ok {
+		t.Errorf("a taken twice")
+	}
+	if _, ok := p.take("missing"); ok {
+		t.Errorf("missing is not pushed")
+	}
+	if n := atomic.LoadInt32(&handled); n != int32(len(paths)) {
+		t.Errorf("expected %d paths handled once each, got %d", len(paths), n)
+	}
+}
+
+func TestWorkerPoolLimit(t *testing.T) {
+	w := newWorkers(1)
+	defer w.stop()
+
+	p := newWorkerPool(w, func(path string) string { return path })
+
+	paths := make([]string, pendingPerWorker+5)
+	for i := range paths {
+		paths[i] = fmt.Sprint(i)
+	}
+	p.push(paths)
+
+	for i, path := range paths {
+		if _, ok := p.take(path); ok != (i < pendingPerWorker) {
+			t.Errorf("%s: expected pushed %v, got %v", path, i < pendingPerWorker, ok)
+		}
+	}
+}
+
+func TestWorkersShared(t *testing.T) {
+	w := newWorkers(2)
+	defer w.stop()
+
+	var running, most int32
+	handle := func(path string) string {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&most)
+			if n <= m || atomic.CompareAndSwapInt32(&most, m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&running, -1)
+
+		return path
+	}
+	dirs, files := newWorkerPool(w, handle), newWorkerPool(w, handle)
+
+	paths := []string{"a", "b", "c", "d", "e", "f"}
+	dirs.push(paths)
+	files.push(paths)
+	time.Sleep(20 * time.Millisecond)
+
+	for _, path := range paths {
+		dirs.take(path)
+		files.take(path)
+	}
+
+	// the traversal takes part as well when a path is not started yet
+	if n := atomic.LoadInt32(&most); n > 3 {
+		t.Errorf("expected at most 2 workers and the traversal, got %d at once", n)
+	}
+}
@@ -0,0 +1,82 @@
+package tree
+
+import (
+	"io/fs"
+	"strings"
+)
+
+// listing is a directory read by a worker.
+type listing struct {
+	info  fs.FileInfo
+	infos []fs.FileInfo
+	err   error
+}
+
+// prefetcher reads subdirectories of every listed directory ahead of the
+// walk. It only does raw reads, so filtering, sorting and output order
+// stay the same as without it.
+type prefetcher struct {
+	pool *workerPool[listing]
+	w    *Walker
+	// maxDepth is the depth limit of the walk unless whole subtrees are
+	// read anyway
+	maxDepth int
+}
+
+func newPrefetcher(w *Walker) *prefetcher {
+	maxDepth := w.opts.MaxDepth
+
+	if readsAhead(w.opts) {
+		maxDepth = 0
+	}
+
+	return &prefetcher{w: w, maxDepth: maxDepth, pool: newWorkerPool(w.workers, func(path string) listing {
+		info, infos, err := w.listDir(path)
+
+		return listing{info: info, infos: infos, err: err}
+	})}
+}
+
+// schedule queues subdirectories which are going to be listed later.
+func (p *prefetcher) schedule(path string, infos []fs.FileInfo) {
+	if p == nil || p.maxDepth != 0 && p.depth(path) >= p.maxDepth {
+		return
+	}
+
+	var paths []string
+
+	for _, info := range infos {
+		if info.IsDir() && !p.w.skipEntry(path, info) {
+			paths = append(paths, join(p.w.fsys, path, info.Name()))
+		}
+	}
+
+	p.pool.push(paths)
+}
+
+// list returns a prefetched listing waiting for it if a worker is still
+// reading the directory. Directories which were not scheduled are read
+// right away.
+func (w *Walker) list(path string) (fs.FileInfo, []fs.FileInfo, error) {
+	if w.prefetch == nil {
+		return w.listDir(path)
+	}
+
+	if l, ok := w.prefetch.pool.take(path); ok {
+		return l.info, l.infos, l.err
+	}
+
+	return w.listDir(path)
+}
+
+// depth returns how deep below the root the directory is, the root is at
+// depth 0.
+func (p *prefetcher) depth(path string) int {
+	rel, ok := relPath(p.w.fsys, p.w.root, path)
+
+	if !ok || rel == "." {
+		return 0
+	}
+
+	return strings.Count(rel, "/") + 1
+}
@@ -0,0 +1,32 @@
+package tree
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPrefetchDepthLimit(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"a/b/c/f.txt": ""})
+
+	w, err := NewWalker(Host, root, Options{MaxDepth: 1, Jobs: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	for _, dir := range []string{root, filepath.Join(root, "a")} {
+		_, infos, err := w.listDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.prefetch.schedule(dir, infos)
+	}
+
+	if _, ok := w.prefetch.pool.take(filepath.Join(root, "a")); !ok {
+		t.Errorf("a at the depth limit is not read ahead")
+	}
+	if _, ok := w.prefetch.pool.take(filepath.Join(root, "a", "b")); ok {
+		t.Errorf("a/b below the depth limit is read ahead")
+	}
+}
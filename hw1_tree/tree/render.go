@@ -0,0 +1,106 @@
+package tree
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"time"
+)
+
+// Renderer writes a tree built by Build.
+type Renderer interface {
+	Render(w io.Writer, root *Node) error
+}
+
+// TextRenderer draws the content of the root like the tree command does,
+// one entry per line with file sizes. The root itself is not printed.
+type TextRenderer struct{}
+
+// Render implements Renderer.
+func (TextRenderer) Render(w io.Writer, root *Node) error {
+	out := bufio.NewWriter(w)
+
+	renderText(out, root.Children, "")
+
+	return out.Flush()
+}
+
+func renderText(out *bufio.Writer, nodes []*Node, prefix string) {
+	for index, node := range nodes {
+		isLast := index == len(nodes)-1
+		connector, childPrefix := "├───", prefix+"│\t"
+
+		if isLast {
+			connector, childPrefix = "└───", prefix+"\t"
+		}
+
+		fmt.Fprintf(out, "%s%s%s", prefix, connector, node.Name)
+
+		if !node.IsDir() {
+			if node.Size == 0 {
+				fmt.Fprintf(out, " (empty)")
+			} else {
+				fmt.Fprintf(out, " (%db)", node.Size)
+			}
+		}
+
+		fmt.Fprintf(out, "\n")
+		renderText(out, node.Children, childPrefix)
+	}
+}
+
+// JSONRenderer writes a nested document with the same fields as the JSON
+// output of the tree command: name, type, size, mode, mtime, children.
+type JSONRenderer struct {
+	// Indent is used for every nesting level, the output is compact when
+	// it is empty
+	Indent string
+}
+
+type jsonNode struct {
+	Name     string      `json:"name"`
+	Type     string      `json:"type"`
+	Size     int64       `json:"size"`
+	Mode     string      `json:"mode"`
+	Mtime    string      `json:"mtime"`
+	Children []*jsonNode `json:"children,omitempty"`
+}
+
+// Render implements Renderer.
+func (r JSONRenderer) Render(w io.Writer, root *Node) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", r.Indent)
+
+	return enc.Encode(newJSONNode(root))
+}
+
+func newJSONNode(node *Node) *jsonNode {
+	result := &jsonNode{
+		Name:  node.Name,
+		Type:  nodeType(node),
+		Size:  node.Size,
+		Mode:  node.Mode.String(),
+		Mtime: node.ModTime.Format(time.RFC3339),
+	}
+
+	for _, child := range node.Children {
+		result.Children = append(result.Children, newJSONNode(child))
+	}
+
+	return result
+}
+
+func nodeType(node *Node) string {
+	switch {
+	case node.IsDir():
+		return "directory"
+	case node.Mode&fs.ModeSymlink != 0:
+		return "link"
+	case node.Mode.IsRegular():
+		return "file"
+	default:
+		return "other"
+	}
+}
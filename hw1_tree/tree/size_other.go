@@ -0,0 +1,11 @@
+//go:build !unix
+
+package tree
+
+import "io/fs"
+
+// allocatedSize falls back to the apparent size where stat data does not
+// tell about allocated blocks.
+func allocatedSize(info fs.FileInfo) int64 {
+	return info.Size()
+}
@@ -0,0 +1,22 @@
+//go:build unix
+
+package tree
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// allocatedSize is the size of blocks allocated on disk instead of the
+// apparent size, so sparse files look smaller and small files take at
+// least a whole block.
+func allocatedSize(info fs.FileInfo) int64 {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+
+	if !ok {
+		return info.Size()
+	}
+
+	// st_blocks is always counted in 512-byte units
+	return int64(stat.Blocks) * 512
+}
@@ -0,0 +1,30 @@
+//go:build unix
+
+package tree
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildAllocatedSize(t *testing.T) {
+	root := t.TempDir()
+	sparse, err := os.Create(filepath.Join(root, "sparse.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sparse.Truncate(64 << 20)
+	sparse.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node, err := Build(Host, root, Options{Files: true, Allocated: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(node.Children) != 1 || node.Children[0].Size >= 64<<20 {
+		t.Errorf("expected allocated size of sparse file to be less than apparent, got %+v", node.Children)
+	}
+}
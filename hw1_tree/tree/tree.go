@@ -0,0 +1,201 @@
+// Package tree builds an in-memory tree of any fs.FS, such as os.DirFS,
+// embed.FS, zip.Reader or fstest.MapFS, and renders it as text or JSON.
+// Building and rendering are separate, so the tree can be inspected or
+// rendered several times without reading the file system again. A Walker
+// reads the same tree one directory at a time for output going along with
+// reading, as the tree command does.
+package tree
+
+import (
+	"io/fs"
+	"path"
+	"regexp"
+	"time"
+)
+
+// Node is a file or a directory with its metadata.
+type Node struct {
+	Name string
+	// Path is the root as given joined with names on the way to the node,
+	// slash separated for every file system but Host
+	Path string
+	Mode fs.FileMode
+	// Size is the size of everything beneath directories with
+	// Options.DiskUsage, and the allocated size with Options.Allocated
+	Size    int64
+	ModTime time.Time
+	// Sys is the underlying data of the file system, e.g. *syscall.Stat_t
+	Sys any
+	// Children are set for directories only, sorted by Options.Less
+	Children []*Node
+	// Loaded tells whether children were read, Truncated whether there is
+	// content cut off by the depth limit
+	Loaded    bool
+	Truncated bool
+	// Usage is set for directories with Options.DiskUsage
+	Usage *Usage
+	// Target is set for symbolic links with Options.Links or FollowLinks,
+	// Recursive marks links to directories already listed
+	Target    string
+	Recursive bool
+	// Err is set for directories which could not be read and files which
+	// could not be hashed
+	Err error
+	// Checksum is set for regular files with Options.Hash
+	Checksum string
+	// Matches is the number of lines matching Options.Grep
+	Matches int
+	// Change is one of Unchanged, Added, Removed, Changed for nodes merged
+	// by Merge, OldSize is the size of changed files before
+	Change  int
+	OldSize *int64
+}
+
+// IsDir reports whether the node is a directory.
+func (n *Node) IsDir() bool {
+	return n.Mode.IsDir()
+}
+
+// Info returns metadata of the node as fs.FileInfo.
+func (n *Node) Info() fs.FileInfo {
+	return nodeInfo{n}
+}
+
+type nodeInfo struct {
+	node *Node
+}
+
+func (info nodeInfo) Name() string       { return info.node.Name }
+func (info nodeInfo) Size() int64        { return info.node.Size }
+func (info nodeInfo) Mode() fs.FileMode  { return info.node.Mode }
+func (info nodeInfo) ModTime() time.Time { return info.node.ModTime }
+func (info nodeInfo) IsDir() bool        { return info.node.IsDir() }
+func (info nodeInfo) Sys() any           { return info.node.Sys }
+
+// Usage sums up what is beneath a directory.
+type Usage struct {
+	Size  int64
+	Files int
+}
+
+// node types for Options.Types, combined with bitwise or
+const (
+	TypeRegular = 1 << iota
+	TypeDir
+	TypeSymlink
+	TypeSocket
+	TypeDevice
+	TypeExecutable
+)
+
+// Options controls which entries get into the tree.
+type Options struct {
+	// Files includes files, otherwise only directories are kept
+	Files bool
+	// MaxDepth limits how deep directories are read, 0 means no limit
+	MaxDepth int
+	// Include keeps only files whose name matches one of the patterns,
+	// Exclude drops files and directories matching any of them. Patterns
+	// use path.Match syntax.
+	Include []string
+	Exclude []string
+	// Hidden includes entries whose names start with a dot, they are
+	// skipped by default
+	Hidden bool
+	// Less orders children of a directory, by name when nil
+	Less func(a, b *Node) bool
+	// Grep keeps only files with lines matching the pattern
+	Grep *regexp.Regexp
+	// MinSize, MaxSize, Newer and Older select files by size and
+	// modification time, zero values do not limit, Types is a combination
+	// of TypeRegular, TypeDir, ..., zero selects all
+	MinSize int64
+	MaxSize int64
+	Newer   time.Time
+	Older   time.Time
+	Types   int
+	// GitIgnore skips entries ignored by .gitignore files of their
+	// directory and its parents up to the repository root
+	GitIgnore bool
+	// DiskUsage sums up sizes of directories content, Allocated takes
+	// sizes of allocated disk blocks instead of apparent sizes
+	DiskUsage bool
+	Allocated bool
+	// Links reads targets of symbolic links, FollowLinks descends into
+	// linked directories as well
+	Links       bool
+	FollowLinks bool
+	// Jobs is the number of workers reading directories ahead and hashing
+	// files, without it files are hashed by as many workers as processors
+	Jobs int
+	// Hash is one of HashNone, HashSHA256, HashMD5, HashCRC32
+	Hash int
+	// Stream makes a Walker read directories in chunks and yield them
+	// unsorted, directories read ahead are still read whole
+	Stream bool
+}
+
+// Build reads root of fsys and everything beneath it down to the depth
+// limit. Use "." for the root of the file system, or Host and an OS path.
+// Directories which cannot be read are kept with Err set, their errors
+// are returned joined along with the tree.
+func Build(fsys fs.FS, root string, opts Options) (*Node, error) {
+	w, err := NewWalker(fsys, root, opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer w.Close()
+
+	node, err := w.readRoot()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if node.IsDir() {
+		w.load(node, 1)
+	}
+
+	return node, w.Err()
+}
+
+// load reads subdirectories of a read directory and hashes its files,
+// directories at the depth limit are read only to tell whether they are
+// truncated.
+func (w *Walker) load(dir *Node, depth int) {
+	w.hasher.schedule(dir.Children)
+
+	for _, child := range dir.Children {
+		w.fill(child)
+
+		if !child.IsDir() || child.Recursive {
+			continue
+		}
+
+		w.Read(child)
+
+		if w.opts.MaxDepth != 0 && depth >= w.opts.MaxDepth {
+			child.Truncated = len(child.Children) != 0
+			child.Children = nil
+
+			continue
+		}
+
+		if child.Err == nil {
+			w.load(child, depth+1)
+		}
+	}
+}
+
+// validatePatterns reports the first malformed pattern.
+func validatePatterns(opts Options) error {
+	for _, pattern := range append(opts.Include, opts.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
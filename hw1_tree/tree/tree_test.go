@@ -0,0 +1,195 @@
+package tree
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var testFS = fstest.MapFS{
+	"README.md":          {Data: []byte("# readme\n")},
+	"cmd/main.go":        {Data: []byte("package main\n")},
+	"cmd/main_test.go":   {Data: []byte("package main\n")},
+	"docs/img/logo.png":  {Data: []byte("png")},
+	"docs/index.html":    {Data: []byte("<html></html>")},
+	"empty":              {Mode: fs.ModeDir | 0755},
+	"vendor/lib/lib.go":  {Data: []byte("package lib\n")},
+	"vendor/lib/LICENSE": {Data: []byte("MIT")},
+}
+
+func render(t *testing.T, root *Node) string {
+	t.Helper()
+
+	out := new(bytes.Buffer)
+	if err := (TextRenderer{}).Render(out, root); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	return out.String()
+}
+
+const testFullResult = "├───README.md (9b)\n" +
+	"├───cmd\n" +
+	"│	├───main.go (13b)\n" +
+	"│	└───main_test.go (13b)\n" +
+	"├───docs\n" +
+	"│	├───img\n" +
+	"│	│	└───logo.png (3b)\n" +
+	"│	└───index.html (13b)\n" +
+	"├───empty\n" +
+	"└───vendor\n" +
+	"	└───lib\n" +
+	"		├───LICENSE (3b)\n" +
+	"		└───lib.go (12b)\n"
+
+func TestBuildFull(t *testing.T) {
+	root, err := Build(testFS, ".", Options{Files: true})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	if result := render(t, root); result != testFullResult {
+		t.Errorf("results not match\nGot:\n%v\nExpected:\n%v", result, testFullResult)
+	}
+}
+
+const testFilteredResult = "├───cmd\n" +
+	"│	└───main.go (13b)\n" +
+	"└───docs\n" +
+	"	└───index.html (13b)\n"
+
+func TestBuildFiltered(t *testing.T) {
+	opts := Options{
+		Files:   true,
+		Include: []string{"*.go", "*.html"},
+		Exclude: []string{"vendor", "*_test.go"},
+	}
+	root, err := Build(testFS, ".", opts)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	if result := render(t, root); result != testFilteredResult {
+		t.Errorf("results not match\nGot:\n%v\nExpected:\n%v", result, testFilteredResult)
+	}
+}
+
+const testDirsResult = "├───cmd\n" +
+	"├───docs\n" +
+	"│	└───img\n" +
+	"├───empty\n" +
+	"└───vendor\n"
+
+func TestBuildDirsWithDepth(t *testing.T) {
+	root, err := Build(testFS, ".", Options{MaxDepth: 2, Exclude: []string{"lib"}})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	if result := render(t, root); result != testDirsResult {
+		t.Errorf("results not match\nGot:\n%v\nExpected:\n%v", result, testDirsResult)
+	}
+}
+
+func TestBuildSubdirectoryAndOrder(t *testing.T) {
+	bySize := func(a, b *Node) bool { return a.Size > b.Size }
+	root, err := Build(testFS, "cmd", Options{Files: true, Less: bySize})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	if root.Name != "cmd" || root.Path != "cmd" || len(root.Children) != 2 {
+		t.Fatalf("unexpected root %+v", root)
+	}
+	if root.Children[0].Path != "cmd/main.go" {
+		t.Errorf("expected stable order for equal sizes, got %s first", root.Children[0].Path)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	if _, err := Build(testFS, "missing", Options{}); err == nil {
+		t.Errorf("expected error for missing root")
+	}
+	if _, err := Build(testFS, ".", Options{Include: []string{"["}}); err == nil {
+		t.Errorf("expected error for bad pattern")
+	}
+}
+
+func TestBuildOSDir(t *testing.T) {
+	root, err := Build(os.DirFS("../testdata"), "project", Options{Files: true})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	expected := "├───file.txt (19b)\n└───gopher.png (70372b)\n"
+	if result := render(t, root); result != expected {
+		t.Errorf("results not match\nGot:\n%v\nExpected:\n%v", result, expected)
+	}
+}
+
+func TestJSONRenderer(t *testing.T) {
+	mtime := time.Date(2020, 11, 15, 9, 14, 58, 0, time.UTC)
+	fsys := fstest.MapFS{
+		"a/b.txt": {Data: []byte("hello"), ModTime: mtime, Mode: 0644},
+	}
+	root, err := Build(fsys, "a", Options{Files: true})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	out := new(bytes.Buffer)
+	if err := (JSONRenderer{}).Render(out, root); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	var result struct {
+		Name     string
+		Type     string
+		Children []struct {
+			Name  string
+			Type  string
+			Size  int64
+			Mode  string
+			Mtime string
+		}
+	}
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON %s\n%s", err, out)
+	}
+	if result.Name != "a" || result.Type != "directory" || len(result.Children) != 1 {
+		t.Fatalf("unexpected root %+v", result)
+	}
+
+	child := result.Children[0]
+	if child.Name != "b.txt" || child.Type != "file" || child.Size != 5 || child.Mode != "-rw-r--r--" || child.Mtime != "2020-11-15T09:14:58Z" {
+		t.Errorf("unexpected child %+v", child)
+	}
+}
+
+func TestBuildHidden(t *testing.T) {
+	fsys := fstest.MapFS{
+		".git/HEAD": {Data: []byte("ref")},
+		".env":      {Data: []byte("A=1")},
+		"main.go":   {Data: []byte("package main\n")},
+	}
+
+	root, err := Build(fsys, ".", Options{Files: true})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if len(root.Children) != 1 || root.Children[0].Name != "main.go" {
+		t.Errorf("expected hidden entries to be skipped, got %d children", len(root.Children))
+	}
+
+	root, err = Build(fsys, ".", Options{Files: true, Hidden: true})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if len(root.Children) != 3 {
+		t.Errorf("expected hidden entries to be included, got %d children", len(root.Children))
+	}
+}
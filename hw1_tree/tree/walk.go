@@ -0,0 +1,299 @@
+package tree
+
+import (
+	"errors"
+	"io/fs"
+	"runtime"
+	"sort"
+)
+
+// Walker reads a tree one directory at a time, so the output can go along
+// with reading. Directories which had to be read ahead of time (e.g. to
+// find out whether anything inside matches the patterns) carry their
+// already filtered and sorted content. Errors of directories below the
+// root do not stop the walk, they are collected for Err.
+type Walker struct {
+	fsys fs.FS
+	// root is where the walk starts, directories below the depth limit
+	// are not read ahead unless whole subtrees are read anyway
+	root string
+	opts Options
+	errs []error
+	// visited holds directories listed so far when links are followed
+	visited  map[fileKey]bool
+	ignore   *gitIgnore
+	workers  *workers
+	prefetch *prefetcher
+	hasher   *hasher
+}
+
+// NewWalker prepares a walk of root of fsys, Close releases its workers
+// when the walk is done.
+func NewWalker(fsys fs.FS, root string, opts Options) (*Walker, error) {
+	if err := validatePatterns(opts); err != nil {
+		return nil, err
+	}
+
+	w := &Walker{fsys: fsys, root: root, opts: opts}
+
+	if opts.FollowLinks {
+		w.visited = make(map[fileKey]bool)
+	}
+
+	if opts.GitIgnore {
+		w.ignore = newGitIgnore()
+	}
+
+	// reading ahead and hashing share the workers, so Jobs bounds both,
+	// without it files are hashed by as many workers as processors
+	count := opts.Jobs
+
+	if count == 0 && opts.Hash != HashNone {
+		count = runtime.NumCPU()
+	}
+
+	// a single job is the walk itself
+	if count > 1 {
+		w.workers = newWorkers(count)
+	}
+
+	// listings are read whole by the workers, so streaming goes without them
+	if w.workers != nil && opts.Jobs > 1 && !opts.Stream {
+		w.prefetch = newPrefetcher(w)
+	}
+
+	if opts.Hash != HashNone {
+		w.hasher = newHasher(w)
+	}
+
+	return w, nil
+}
+
+// Close stops the workers.
+func (w *Walker) Close() {
+	if w.workers != nil {
+		w.workers.stop()
+	}
+}
+
+// Err returns errors of directories and files which could not be read,
+// joined.
+func (w *Walker) Err() error {
+	return errors.Join(w.errs...)
+}
+
+func (w *Walker) addErr(err error) {
+	w.errs = append(w.errs, err)
+}
+
+// Root returns the root and an iterator over its content.
+func (w *Walker) Root() (*Node, Iterator, error) {
+	if !w.opts.Stream {
+		root, err := w.readRoot()
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !root.Loaded {
+			return nil, nil, &fs.PathError{Op: "readdir", Path: w.root, Err: errors.New("not a directory")}
+		}
+
+		return root, w.newSliceIterator(root), nil
+	}
+
+	info, err := fs.Stat(w.fsys, w.root)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root := w.newNode(w.root, info)
+	files, err := w.openStream(root)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return root, files, nil
+}
+
+// readRoot reads the root with its content when it is a directory.
+func (w *Walker) readRoot() (*Node, error) {
+	info, err := fs.Stat(w.fsys, w.root)
+
+	if err != nil {
+		return nil, err
+	}
+
+	root := w.newNode(w.root, info)
+
+	if !root.IsDir() {
+		return root, nil
+	}
+
+	files, summary, err := w.readDir(w.root)
+
+	if err != nil {
+		return nil, err
+	}
+
+	root.Children, root.Loaded = files, true
+
+	if w.opts.DiskUsage {
+		root.setUsage(summary.Usage)
+	}
+
+	return root, nil
+}
+
+// Open returns an iterator over the content of a directory below the
+// root. Directories which cannot be read get the error, which is
+// collected for Err as well.
+func (w *Walker) Open(dir *Node) (Iterator, error) {
+	if !w.opts.Stream || dir.Loaded {
+		err := w.Read(dir)
+
+		return w.newSliceIterator(dir), err
+	}
+
+	files, err := w.openStream(dir)
+
+	if err != nil {
+		w.addErr(err)
+		dir.Err = err
+
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// Read sets children of a directory below the root unless they are
+// already read. Directories which cannot be read get the error, which is
+// collected for Err as well.
+func (w *Walker) Read(dir *Node) error {
+	if dir.Loaded {
+		return dir.Err
+	}
+
+	dir.Children, _, dir.Err = w.readDir(dir.Path)
+	dir.Loaded = true
+
+	if dir.Err != nil {
+		w.addErr(dir.Err)
+	}
+
+	return dir.Err
+}
+
+func (w *Walker) readDir(path string) ([]*Node, summary, error) {
+	info, infos, err := w.list(path)
+
+	if err != nil {
+		return nil, summary{}, err
+	}
+
+	w.visit(path, info)
+	w.prefetch.schedule(path, infos)
+
+	// directories read ahead and links followed depend on what was already
+	// listed, so entries are handled in the same order on every run
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name() < infos[j].Name()
+	})
+
+	files, summary := w.filterEntries(path, infos)
+
+	if !w.opts.Files {
+		files = filterOutFiles(files)
+	}
+
+	w.sort(files)
+
+	return files, summary, nil
+}
+
+// listDir returns info of the directory itself and its unsorted content.
+func (w *Walker) listDir(path string) (fs.FileInfo, []fs.FileInfo, error) {
+	dir, err := openDir(w.fsys, path)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer dir.Close()
+
+	info, err := dir.Stat()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	infos, err := readInfos(dir, -1)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return info, infos, nil
+}
+
+// visit remembers a listed directory, so links to it are not followed.
+func (w *Walker) visit(path string, info fs.FileInfo) {
+	if w.visited == nil {
+		return
+	}
+
+	if key, ok := newFileKey(w.fsys, path, info); ok {
+		w.visited[key] = true
+	}
+}
+
+func (w *Walker) sort(files []*Node) {
+	less := w.opts.Less
+
+	if less == nil {
+		less = byName
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return less(files[i], files[j])
+	})
+}
+
+func (w *Walker) newNode(path string, info fs.FileInfo) *Node {
+	node := &Node{
+		Name:    info.Name(),
+		Path:    path,
+		Mode:    info.Mode(),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		Sys:     info.Sys(),
+	}
+
+	if w.opts.Allocated {
+		node.Size = allocatedSize(info)
+	}
+
+	return node
+}
+
+func (n *Node) setUsage(usage Usage) {
+	n.Usage = &usage
+	n.Size = usage.Size
+}
+
+func filterOutFiles(files []*Node) []*Node {
+	folders := make([]*Node, 0)
+
+	for _, file := range files {
+		if !file.IsDir() {
+			continue
+		}
+
+		folders = append(folders, file)
+	}
+
+	return folders
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"hw1_tree/tree"
+)
+
+const (
+	watchNone = iota
+	// watchRender prints the whole tree again after every change
+	watchRender
+	// watchEvents prints added, removed and changed entries only
+	watchEvents
+)
+
+// pollInterval is how often the tree is read again when the system cannot
+// tell about changes
+const pollInterval = time.Second
+
+// watchTick is how often a waiting watcher checks whether it is stopped,
+// changes coming closer than that are handled at once, so a burst like an
+// unpacked archive is printed once
+const watchTick = 100 * time.Millisecond
+
+// watchSettle limits how long a burst is waited out, so files written
+// all the time like logs do not hold the output back
+const watchSettle = time.Second
+
+// clearScreen moves the cursor home and erases the terminal
+const clearScreen = "\033[H\033[2J"
+
+// watcher wakes up the watch loop when watched directories could have
+// changed, the loop compares the trees to find out what happened.
+type watcher interface {
+	// watch adds directories to watch and returns how many of them were
+	// not watched yet
+	watch(dirs []string) int
+	// wait returns false when stop is closed
+	wait(stop <-chan struct{}) (bool, error)
+	close()
+}
+
+// pollWatcher wakes up every interval.
+type pollWatcher struct {
+	interval time.Duration
+}
+
+func (w pollWatcher) watch(dirs []string) int {
+	return 0
+}
+
+func (w pollWatcher) wait(stop <-chan struct{}) (bool, error) {
+	select {
+	case <-stop:
+		return false, nil
+	case <-time.After(w.interval):
+		return true, nil
+	}
+}
+
+func (w pollWatcher) close() {}
+
+// newWatcher falls back to polling when the system watcher is missing.
+func newWatcher() watcher {
+	w, err := newSystemWatcher()
+
+	if err != nil {
+		return pollWatcher{interval: pollInterval}
+	}
+
+	return w
+}
+
+// watchDirs lists directories of a loaded tree, parents go first.
+func watchDirs(path string, files []*tree.Node, dirs []string) []string {
+	for _, file := range files {
+		if !file.IsDir() || file.Recursive || !file.Loaded {
+			continue
+		}
+
+		filePath := filepath.Join(path, file.Name)
+		dirs = watchDirs(filePath, file.Children, append(dirs, filePath))
+	}
+
+	return dirs
+}
+
+// loadWatched reads the tree and watches its directories. The tree is
+// read again while new directories turn up, so what was put in them
+// before they were watched is not missed.
+func loadWatched(w watcher, path string, opts options) (*tree.Node, error) {
+	for {
+		root, err := loadTree(path, opts)
+
+		if root == nil || w.watch(watchDirs(path, root.Children, []string{path})) == 0 {
+			return root, err
+		}
+	}
+}
+
+// watchTree prints the tree and then keeps printing it again, or only the
+// entries which changed, until stop is closed. Errors of directories which
+// could not be read do not stop watching, they go to errOut.
+func watchTree(out io.Writer, errOut io.Writer, path string, opts options, stop <-chan struct{}) error {
+	// an archive is read once, changes of it would not be seen
+	if info, err := os.Stat(path); err == nil && !info.IsDir() && tree.IsArchive(path) {
+		return errors.New("archives cannot be watched: " + path)
+	}
+
+	w := newWatcher()
+	defer w.close()
+
+	old, err := loadWatched(w, path, opts)
+
+	if old == nil {
+		return err
+	}
+
+	printOutWatchError(errOut, errors.Join(renderLoaded(out, path, old, opts), err))
+
+	for {
+		changed, err := w.wait(stop)
+
+		if err != nil || !changed {
+			return err
+		}
+
+		current, err := loadWatched(w, path, opts)
+
+		if current == nil {
+			return err
+		}
+
+		events := diffEvents(path, tree.Merge(old.Children, current.Children, lessFunc(opts), true), nil)
+		old = current
+
+		if len(events) == 0 {
+			continue
+		}
+
+		if opts.watch == watchEvents {
+			for _, event := range events {
+				fmt.Fprintln(out, event)
+			}
+
+			continue
+		}
+
+		// trees piped somewhere are separated by an empty line instead
+		if opts.format == formatText && isTerminal(out) {
+			fmt.Fprint(out, clearScreen)
+		} else {
+			fmt.Fprintln(out)
+		}
+
+		printOutWatchError(errOut, errors.Join(renderLoaded(out, path, current, opts), err))
+	}
+}
+
+// diffEvents lists marked entries of a merged tree, content of added or
+// removed directories is not listed.
+func diffEvents(path string, files []*tree.Node, events []string) []string {
+	for _, file := range files {
+		filePath := filepath.Join(path, file.Name)
+
+		if file.Change != tree.Unchanged {
+			events = append(events, diffNames[file.Change]+" "+filePath)
+
+			continue
+		}
+
+		if file.IsDir() {
+			events = diffEvents(filePath, file.Children, events)
+		}
+	}
+
+	return events
+}
+
+func printOutWatchError(errOut io.Writer, err error) {
+	if err != nil {
+		fmt.Fprintf(errOut, "tree: %s\n", err)
+	}
+}
@@ -0,0 +1,111 @@
+//go:build linux
+
+package main
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"time"
+)
+
+// inotifyMask selects changes of directory content, writes and attributes
+// are included as they change sizes and modification times
+const inotifyMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO |
+	syscall.IN_MODIFY | syscall.IN_ATTRIB | syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF
+
+// inotifyWatcher reads inotify events through a non-blocking descriptor,
+// so reads have deadlines and waiting can be stopped. All directories are
+// watched through the one descriptor, so events queued while the tree is
+// read again are kept for the next wait.
+type inotifyWatcher struct {
+	file *os.File
+	fd   int
+	// wds holds watch descriptors given so far, a directory created again
+	// gets a new one
+	wds map[int]bool
+	// polling is set when the system runs out of watches
+	polling bool
+}
+
+func newSystemWatcher() (watcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_NONBLOCK | syscall.IN_CLOEXEC)
+
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+
+	return &inotifyWatcher{file: os.NewFile(uintptr(fd), "inotify"), fd: fd, wds: make(map[int]bool)}, nil
+}
+
+func (w *inotifyWatcher) watch(dirs []string) int {
+	added := 0
+
+	for _, dir := range dirs {
+		if w.polling {
+			return 0
+		}
+
+		wd, err := syscall.InotifyAddWatch(w.fd, dir, inotifyMask)
+
+		// a directory gone or closed meanwhile is reported by the watch of
+		// its parent, other errors like too many watches leave polling
+		switch {
+		case err == syscall.ENOENT || err == syscall.EACCES:
+		case err != nil:
+			w.polling = true
+		case !w.wds[wd]:
+			w.wds[wd] = true
+			added++
+		}
+	}
+
+	return added
+}
+
+func (w *inotifyWatcher) wait(stop <-chan struct{}) (bool, error) {
+	if w.polling {
+		return pollWatcher{interval: pollInterval}.wait(stop)
+	}
+
+	// room for a few events with names up to NAME_MAX
+	buf := make([]byte, 16*(syscall.SizeofInotifyEvent+256))
+
+	for {
+		select {
+		case <-stop:
+			return false, nil
+		default:
+		}
+
+		if err := w.read(buf); err == nil {
+			break
+		} else if !errors.Is(err, os.ErrDeadlineExceeded) {
+			return false, err
+		}
+	}
+
+	// the contents of events do not matter, the rest of the burst is
+	// skipped until it is quiet or it took too long
+	for settle := time.Now().Add(watchSettle); time.Now().Before(settle); {
+		if w.read(buf) != nil {
+			break
+		}
+	}
+
+	return true, nil
+}
+
+func (w *inotifyWatcher) read(buf []byte) error {
+	if err := w.file.SetReadDeadline(time.Now().Add(watchTick)); err != nil {
+		return err
+	}
+
+	_, err := w.file.Read(buf)
+
+	return err
+}
+
+func (w *inotifyWatcher) close() {
+	w.file.Close()
+}
@@ -0,0 +1,70 @@
+//go:build linux
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestInotifyWatcherAddsOnce(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"a/f.txt": ""})
+
+	w, err := newSystemWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.close()
+
+	dirs := []string{root, filepath.Join(root, "a")}
+	if added := w.watch(dirs); added != 2 {
+		t.Errorf("expected 2 new directories, got %d", added)
+	}
+	if added := w.watch(dirs); added != 0 {
+		t.Errorf("expected no new directories, got %d", added)
+	}
+}
+
+func TestInotifyWatcherBusyFile(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "log")
+
+	w, err := newSystemWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.close()
+	w.watch([]string{root})
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	// the log is written more often than the watcher waits for quiet
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(watchTick / 4):
+				file.WriteString("line\n")
+			}
+		}
+	}()
+
+	start := time.Now()
+	changed, err := w.wait(nil)
+	if !changed || err != nil {
+		t.Fatalf("expected wake up, got %v, %v", changed, err)
+	}
+	if elapsed := time.Since(start); elapsed > watchSettle+time.Second {
+		t.Errorf("waited %s for a busy file", elapsed)
+	}
+}
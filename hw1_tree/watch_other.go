@@ -0,0 +1,10 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+// newSystemWatcher is missing outside of Linux, the tree is polled.
+func newSystemWatcher() (watcher, error) {
+	return nil, errors.New("no system watcher")
+}
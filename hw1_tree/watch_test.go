@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"hw1_tree/tree"
+)
+
+func TestDiffEvents(t *testing.T) {
+	oldRoot, newRoot := makeDiffTrees(t)
+	opts := options{keepFiles: true}
+
+	oldTree, err := loadTree(oldRoot, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newTree, err := loadTree(newRoot, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := diffEvents(newRoot, tree.Merge(oldTree.Children, newTree.Children, lessFunc(opts), false), nil)
+	expected := []string{
+		"changed " + filepath.Join(newRoot, "a", "f.txt"),
+		"removed " + filepath.Join(newRoot, "gone"),
+		"changed " + filepath.Join(newRoot, "kind"),
+		"added " + filepath.Join(newRoot, "new"),
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected %q, got %q", expected, events)
+	}
+}
+
+func TestWatchDirs(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"a/b/c.txt": "c", "a/d.txt": "d", "e/f.txt": "f"})
+
+	loaded, err := loadTree(root, options{keepFiles: true, maxDepth: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dirs := watchDirs(root, loaded.Children, []string{root})
+	expected := []string{root, filepath.Join(root, "a"), filepath.Join(root, "e")}
+	if !reflect.DeepEqual(dirs, expected) {
+		t.Errorf("expected %q, got %q", expected, dirs)
+	}
+}
+
+// syncBuffer is written by the watch loop while the test reads it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func waitOutput(t *testing.T, out *syncBuffer, expected string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(out.String(), expected) {
+		if time.Now().After(deadline) {
+			t.Fatalf("%q not printed, got:\n%s", expected, out.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWatchTreeEvents(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"a/b.txt": "b"})
+
+	out, errOut := new(syncBuffer), new(syncBuffer)
+	stop := make(chan struct{})
+	done := make(chan error)
+	opts := options{keepFiles: true, color: colorNever, watch: watchEvents}
+
+	go func() {
+		done <- watchTree(out, errOut, root, opts, stop)
+	}()
+
+	waitOutput(t, out, "└───b.txt (1b)\n")
+	writeFiles(t, root, map[string]string{"a/new/c.txt": "c"})
+	waitOutput(t, out, "added "+filepath.Join(root, "a", "new")+"\n")
+	writeFiles(t, root, map[string]string{"a/new/d.txt": "d"})
+	waitOutput(t, out, "added "+filepath.Join(root, "a", "new", "d.txt")+"\n")
+	if err := os.Remove(filepath.Join(root, "a", "b.txt")); err != nil {
+		t.Fatal(err)
+	}
+	waitOutput(t, out, "removed "+filepath.Join(root, "a", "b.txt")+"\n")
+	// same size, only the modification time differs
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(root, "a", "new", "c.txt"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	waitOutput(t, out, "changed "+filepath.Join(root, "a", "new", "c.txt")+"\n")
+
+	close(stop)
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error %s", err)
+	}
+	if errOut.String() != "" {
+		t.Errorf("unexpected errors %q", errOut.String())
+	}
+}
+
+func TestWatchTreeMissingRoot(t *testing.T) {
+	err := watchTree(new(bytes.Buffer), new(bytes.Buffer), filepath.Join(t.TempDir(), "missing"), options{}, nil)
+	if err == nil {
+		t.Errorf("expected error for missing root")
+	}
+}
+
+func TestWatchTreeArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "files.zip")
+	writeZip(t, path, archiveFiles)
+
+	err := watchTree(new(bytes.Buffer), new(bytes.Buffer), path, options{}, nil)
+	if err == nil || !strings.Contains(err.Error(), "archives cannot be watched") {
+		t.Errorf("expected archive to be rejected, got %v", err)
+	}
+}
+
+func TestPollWatcher(t *testing.T) {
+	w := pollWatcher{interval: time.Millisecond}
+	if changed, err := w.wait(nil); !changed || err != nil {
+		t.Errorf("expected wake up, got %v, %v", changed, err)
+	}
+
+	stop := make(chan struct{})
+	close(stop)
+	w.interval = time.Hour
+	if changed, err := w.wait(stop); changed || err != nil {
+		t.Errorf("expected stop, got %v, %v", changed, err)
+	}
+}